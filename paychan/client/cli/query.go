@@ -7,13 +7,15 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/client/utils"
 	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/kava-labs/cosmos-paychan/paychan/types"
 )
 
 // GetQueryCmd returns the cli query commands for this module
-func GetQueryCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
+func GetQueryCmd(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	queryCmd := &cobra.Command{
 		Use:                        types.ModuleName,
 		Short:                      "Querying commands for the paychan module",
@@ -23,14 +25,17 @@ func GetQueryCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 	}
 
 	queryCmd.AddCommand(client.GetCommands(
-		GetCmd_GetChannel(storeKey, cdc),
-		GetCmd_GetSubmittedUpdate(storeKey, cdc),
+		GetCmd_GetChannel(queryRoute, cdc),
+		GetCmd_GetSubmittedUpdate(queryRoute, cdc),
+		GetCmd_GetChannels(queryRoute, cdc),
+		GetCmd_GetParams(queryRoute, cdc),
+		GetCmd_GetEscrow(queryRoute, cdc),
 	)...)
 
 	return queryCmd
 }
 
-func GetCmd_GetChannel(storeKey string, cdc *codec.Codec) *cobra.Command {
+func GetCmd_GetChannel(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
 		Use:   "paychan [paychan-id]",
 		Args:  cobra.ExactArgs(1),
@@ -45,15 +50,16 @@ func GetCmd_GetChannel(storeKey string, cdc *codec.Codec) *cobra.Command {
 			}
 
 			// Query the node
-			res, err := cliCtx.QueryStore(types.GetChannelKey(channelID), storeKey)
+			bz, err := cdc.MarshalJSON(types.NewQueryChannelParams(channelID))
 			if err != nil {
 				return err
 			}
-			if len(res) == 0 {
-				return fmt.Errorf("No channel found with id %v", channelID)
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetChannel), bz)
+			if err != nil {
+				return err
 			}
 			var channel types.Channel
-			if err := cdc.UnmarshalBinaryLengthPrefixed(res, &channel); err != nil {
+			if err := cdc.UnmarshalJSON(res, &channel); err != nil {
 				return err
 			}
 
@@ -63,7 +69,7 @@ func GetCmd_GetChannel(storeKey string, cdc *codec.Codec) *cobra.Command {
 	}
 }
 
-func GetCmd_GetSubmittedUpdate(storeKey string, cdc *codec.Codec) *cobra.Command {
+func GetCmd_GetSubmittedUpdate(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
 		Use:   "update [paychan-id]",
 		Args:  cobra.ExactArgs(1),
@@ -78,15 +84,16 @@ func GetCmd_GetSubmittedUpdate(storeKey string, cdc *codec.Codec) *cobra.Command
 			}
 
 			// Query the node
-			res, err := cliCtx.QueryStore(types.GetSubmittedUpdateKey(channelID), storeKey)
+			bz, err := cdc.MarshalJSON(types.NewQueryChannelParams(channelID))
 			if err != nil {
 				return err
 			}
-			if len(res) == 0 {
-				return fmt.Errorf("No submitted update found for channel with id %v", channelID)
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetSubmittedUpdate), bz)
+			if err != nil {
+				return err
 			}
 			var sUpdate types.SubmittedUpdate
-			if err := cdc.UnmarshalBinaryLengthPrefixed(res, &sUpdate); err != nil {
+			if err := cdc.UnmarshalJSON(res, &sUpdate); err != nil {
 				return err
 			}
 
@@ -95,3 +102,107 @@ func GetCmd_GetSubmittedUpdate(storeKey string, cdc *codec.Codec) *cobra.Command
 		},
 	}
 }
+
+func GetCmd_GetChannels(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	flagPage := "page"
+	flagLimit := "limit"
+	flagSender := "sender"
+	flagReceiver := "receiver"
+
+	cmd := &cobra.Command{
+		Use:   "channels",
+		Args:  cobra.NoArgs,
+		Short: "List open channels, optionally only those with a given sender or receiver",
+		Long:  "List open channels. A multiparty channel is listed under each of its participants as both sender and receiver.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			// Parse inputs
+			var sender, receiver sdk.AccAddress
+			var err error
+			if s := viper.GetString(flagSender); s != "" {
+				sender, err = sdk.AccAddressFromBech32(s)
+				if err != nil {
+					return err
+				}
+			}
+			if r := viper.GetString(flagReceiver); r != "" {
+				receiver, err = sdk.AccAddressFromBech32(r)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Query the node
+			params := types.NewQueryChannelsParams(viper.GetInt(flagPage), viper.GetInt(flagLimit), sender, receiver)
+			bz, err := cdc.MarshalJSON(params)
+			if err != nil {
+				return err
+			}
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetChannels), bz)
+			if err != nil {
+				return err
+			}
+			var channels types.Channels
+			if err := cdc.UnmarshalJSON(res, &channels); err != nil {
+				return err
+			}
+
+			// Print result
+			return cliCtx.PrintOutput(channels)
+		},
+	}
+	cmd.Flags().Int(flagPage, 1, "Page of results to return.")
+	cmd.Flags().Int(flagLimit, types.MaxChannelsLimit, fmt.Sprintf("Maximum number of channels per page, at most %d.", types.MaxChannelsLimit))
+	cmd.Flags().String(flagSender, "", "Only list channels this address can pay into (bech32).")
+	cmd.Flags().String(flagReceiver, "", "Only list channels that can pay this address (bech32).")
+	return cmd
+}
+
+func GetCmd_GetParams(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "params",
+		Args:  cobra.NoArgs,
+		Short: "Get the current paychan parameters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			// Query the node
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetParams), nil)
+			if err != nil {
+				return err
+			}
+			var params types.Params
+			if err := cdc.UnmarshalJSON(res, &params); err != nil {
+				return err
+			}
+
+			// Print result
+			return cliCtx.PrintOutput(params)
+		},
+	}
+}
+
+func GetCmd_GetEscrow(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "escrow",
+		Args:  cobra.NoArgs,
+		Short: "Get the address of the account holding coins locked in channels, and its balance",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			// Query the node
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetEscrow), nil)
+			if err != nil {
+				return err
+			}
+			var escrow types.Escrow
+			if err := cdc.UnmarshalJSON(res, &escrow); err != nil {
+				return err
+			}
+
+			// Print result
+			return cliCtx.PrintOutput(escrow)
+		},
+	}
+}
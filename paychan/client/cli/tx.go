@@ -1,19 +1,25 @@
 package cli
 
 import (
-	"encoding/json"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/client/utils"
 	"github.com/cosmos/cosmos-sdk/codec"
+	crkeys "github.com/cosmos/cosmos-sdk/crypto/keys"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/multisig"
 
 	"github.com/kava-labs/cosmos-paychan/paychan/types"
 )
@@ -30,15 +36,32 @@ func GetTxCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 
 	txCmd.AddCommand(client.PostCommands(
 		GetCmd_CreateChannel(cdc),
+		GetCmd_CreateMultipartyChannel(cdc),
+		GetCmd_Deposit(cdc),
+		GetCmd_RotateSigningKey(cdc),
 		GetCmd_SubmitPayment(cdc),
+		GetCmd_BatchClose(cdc),
+		GetCmd_Withdraw(cdc),
 		GetCmd_GeneratePayment(cdc),
+		GetCmd_SignPayment(cdc),
+		GetCmd_MultisignPayment(cdc),
+		GetCmd_CooperativeClose(cdc),
+		GetCmd_AuthorizeClose(cdc),
+		GetCmd_RelayClose(cdc),
+		GetCmd_RevealPreimage(cdc),
+		GetCmd_Reclaim(cdc),
 	)...)
 
 	return txCmd
 }
 
 func GetCmd_CreateChannel(cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	flagDisputePeriod := "dispute-period"
+	flagSigningPubKey := "signing-pubkey"
+	flagPenaltyBond := "penalty-bond"
+	flagExpiry := "expiry"
+
+	cmd := &cobra.Command{
 		Use:   "create [receiver-address] [amount]",
 		Short: "Create a new payment channel",
 		Long:  "Create a new unidirectional payment channel from a local address to a remote address, funded with some amount of coins. These coins are removed from the sender account and put into the channel.",
@@ -61,11 +84,181 @@ func GetCmd_CreateChannel(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
+			penaltyBond, err := sdk.ParseCoins(viper.GetString(flagPenaltyBond))
+			if err != nil {
+				return err
+			}
+
+			var signingPubKey crypto.PubKey
+			if s := viper.GetString(flagSigningPubKey); s != "" {
+				signingPubKey, err = sdk.GetAccPubKeyBech32(s)
+				if err != nil {
+					return err
+				}
+			}
+
+			var expiry time.Time
+			if e := viper.GetString(flagExpiry); e != "" {
+				expiry, err = time.Parse(time.RFC3339, e)
+				if err != nil {
+					return err
+				}
+			}
+
 			// Create msg
 			senderAddr := cliCtx.GetFromAddress()
 			msg := types.MsgCreate{
-				Participants: [2]sdk.AccAddress{senderAddr, receiverAddr},
-				Coins:        amount,
+				Participants:  [2]sdk.AccAddress{senderAddr, receiverAddr},
+				Coins:         amount,
+				DisputePeriod: viper.GetDuration(flagDisputePeriod),
+				SigningPubKey: signingPubKey,
+				PenaltyBond:   penaltyBond,
+				Expiry:        expiry,
+			}
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Duration(flagDisputePeriod, 0, "Time the receiver has to respond to a close by the sender, eg 72h. Defaults to the module's default dispute period.")
+	cmd.Flags().String(flagPenaltyBond, "", "Coins to lock up in addition to the channel amount, a share of which goes to the receiver if you try to close with an old payment. See 'query paychan params'.")
+	cmd.Flags().String(flagSigningPubKey, "", "Public key (bech32) to sign payments with instead of the sender's account key, see 'keys show --bech acc'.")
+	cmd.Flags().String(flagExpiry, "", "Time the channel expires (RFC3339, eg 2019-12-31T00:00:00Z). The receiver must close it before then, afterwards the remaining coins are returned to you.")
+	return cmd
+}
+
+func GetCmd_CreateMultipartyChannel(cdc *codec.Codec) *cobra.Command {
+	flagDisputePeriod := "dispute-period"
+	flagThreshold := "threshold"
+
+	cmd := &cobra.Command{
+		Use:   "create-multiparty [address] [amount] [address] [amount] [[address] [amount]...]",
+		Short: "Create a new multiparty payment channel",
+		Long: `Create a new payment channel between two or more participants, funded with the given amount of coins from each (use "" for no coins).
+A bidirectional channel is a multiparty channel with two participants.
+The transaction must be signed by all participants, so generate it with --generate-only and have each participant sign it with 'tx sign'.
+Before signing, participants should exchange a payment returning each their deposit (sequence 0), signed by enough participants, so any can close the channel.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 4 || len(args)%2 != 0 {
+				return fmt.Errorf("requires an address and amount for each of at least two participants, received %d args", len(args))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Parse inputs
+			var participants []sdk.AccAddress
+			var deposits types.Payout
+			for i := 0; i < len(args); i += 2 {
+				addr, err := sdk.AccAddressFromBech32(args[i])
+				if err != nil {
+					return err
+				}
+				amount, err := sdk.ParseCoins(args[i+1])
+				if err != nil {
+					return err
+				}
+				participants = append(participants, addr)
+				deposits = append(deposits, amount)
+			}
+
+			// Create msg
+			msg := types.MsgCreateMultiparty{
+				Participants:  participants,
+				Deposits:      deposits,
+				SigThreshold:  viper.GetUint64(flagThreshold),
+				DisputePeriod: viper.GetDuration(flagDisputePeriod),
+			}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Duration(flagDisputePeriod, 0, "Time participants have to respond to a close by another, eg 72h. Defaults to the module's default dispute period.")
+	cmd.Flags().Uint64(flagThreshold, 0, "Number of participants that must sign each payment. Defaults to all participants.")
+	return cmd
+}
+
+func GetCmd_Deposit(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "deposit [channel-id] [amount]",
+		Short: "Top up a payment channel",
+		Long:  "Add more coins to an existing payment channel. Only the channel's sender can deposit, and not while a close by the sender is pending. Payments signed before the deposit remain valid, any coins they don't cover are returned to the sender on close.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Parse inputs
+			channelID, err := types.NewChannelIDFromString(args[0])
+			if err != nil {
+				return err
+			}
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+
+			// Create msg
+			msg := types.MsgDeposit{
+				ChannelID: channelID,
+				Depositor: cliCtx.GetFromAddress(),
+				Coins:     amount,
+			}
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmd_RotateSigningKey(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-key [channel-id] [new-signing-pubkey]",
+		Short: "Change the key payments in a channel are signed with",
+		Long: `Replace the key the sender signs payments with by a new public key (bech32), see 'keys show --bech acc'. Must be sent from the channel sender's account.
+Payments signed with the old key can't be submitted after the rotation, so re-sign the latest payment with the new key and send it to the receiver.
+A close already submitted with an old payment still goes ahead.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Parse inputs
+			channelID, err := types.NewChannelIDFromString(args[0])
+			if err != nil {
+				return err
+			}
+			pubKey, err := sdk.GetAccPubKeyBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			// Create msg
+			msg := types.MsgRotateSigningKey{
+				ChannelID:        channelID,
+				Sender:           cliCtx.GetFromAddress(),
+				NewSigningPubKey: pubKey,
 			}
 			if err = msg.ValidateBasic(); err != nil {
 				return err
@@ -83,7 +276,7 @@ func GetCmd_SubmitPayment(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "close",
 		Short: "Submit a payment to the blockchain to close the channel.",
-		Long:  fmt.Sprintf("Submit a payment to the blockchain to either close a channel immediately (if you are the receiver) or after a dispute period of %d blocks (if you are the sender).", types.ChannelDisputeTime),
+		Long:  "Submit a payment to the blockchain to either close a channel immediately (if you are the receiver) or after a dispute period (if you are the sender). The dispute period is a module parameter, see `query paychan params`.",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Create cli helpers
@@ -98,7 +291,7 @@ func GetCmd_SubmitPayment(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 			var update types.Update
-			err = json.Unmarshal(bz, &update)
+			err = cdc.UnmarshalJSON(bz, &update)
 			if err != nil {
 				return err
 			}
@@ -120,15 +313,154 @@ func GetCmd_SubmitPayment(cdc *codec.Codec) *cobra.Command {
 	return cmd
 }
 
+func GetCmd_BatchClose(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "close-batch [payment-file] [[payment-file]...]",
+		Short: "Close many channels at once as their receiver.",
+		Long:  "Submit payments for many channels you are the receiver of in one transaction, closing each immediately. A payment that fails to close its channel doesn't stop the others, the outcome for each channel is returned in the transaction result data.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Get the payments to be submitted to the blockchain
+			var updates []types.Update
+			for _, paymentFile := range args {
+				bz, err := ioutil.ReadFile(paymentFile)
+				if err != nil {
+					return err
+				}
+				var update types.Update
+				if err = cdc.UnmarshalJSON(bz, &update); err != nil {
+					return err
+				}
+				updates = append(updates, update)
+			}
+
+			// Create msg
+			msg := types.MsgBatchClose{
+				Updates:  updates,
+				Receiver: cliCtx.GetFromAddress(),
+			}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmd_CooperativeClose(cdc *codec.Codec) *cobra.Command {
+	flagPaymentFile := "payment"
+
+	cmd := &cobra.Command{
+		Use:   "cooperative-close",
+		Short: "Close a channel immediately with a payment signed by all participants.",
+		Long:  "Submit a payment signed by all the channel's participants (see sign-payment) to close the channel immediately, without waiting for a dispute period. It can be submitted by anyone.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Get the payment to be submitted to the blockchain
+			bz, err := ioutil.ReadFile(viper.GetString(flagPaymentFile))
+			if err != nil {
+				return err
+			}
+			var update types.Update
+			err = cdc.UnmarshalJSON(bz, &update)
+			if err != nil {
+				return err
+			}
+
+			// Create msg
+			msg := types.MsgCooperativeClose{
+				Update:    update,
+				Submitter: cliCtx.GetFromAddress(),
+			}
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagPaymentFile, "payment.json", "File to read the payment from.")
+	return cmd
+}
+
+func GetCmd_Withdraw(cdc *codec.Codec) *cobra.Command {
+	flagPaymentFile := "payment"
+
+	cmd := &cobra.Command{
+		Use:   "withdraw",
+		Short: "Withdraw a payment from a channel without closing it.",
+		Long:  "Submit a payment to the blockchain to receive any coins owed to you (the receiver) that haven't already been withdrawn. The channel stays open for further payments.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Get the payment to be submitted to the blockchain
+			bz, err := ioutil.ReadFile(viper.GetString(flagPaymentFile))
+			if err != nil {
+				return err
+			}
+			var update types.Update
+			err = cdc.UnmarshalJSON(bz, &update)
+			if err != nil {
+				return err
+			}
+
+			// Create msg
+			msg := types.MsgWithdraw{
+				Update:   update,
+				Receiver: cliCtx.GetFromAddress(),
+			}
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagPaymentFile, "payment.json", "File to read the payment from.")
+	return cmd
+}
+
 func GetCmd_GeneratePayment(cdc *codec.Codec) *cobra.Command {
 	flagPaymentFile := "filename"
+	flagSequence := "sequence"
+	flagSigningKey := "signing-key"
+	flagHashLock := "hash-lock"
+	flagUnsigned := "unsigned"
 
 	cmd := &cobra.Command{
-		Use:   "pay [channel-id] [sender-amount] [receiver-amount]",
+		Use:   "pay [channel-id] [sender-amount] [receiver-amount] [[amount]...]",
 		Short: "generate a new payment",
 		Long: `Generate a payment file (json) to send to the receiver as a payment.
-Specify the channel id, and the total coins to be received by the channel's sender and receiver when the channel is eventually closed.`,
-		Args: cobra.ExactArgs(3),
+Specify the channel id, and the total coins to be received by the channel's sender and receiver when the channel is eventually closed.
+For multiparty channels give an amount for each participant in order, and a sequence number higher than any previous payment.
+Other participants must add their signatures with the sign-payment command until the channel's signature threshold is met.
+If the channel was created with a signing key, sign with it using --signing-key.
+Conditional payments can be added to unidirectional channels with --hash-lock [hex-sha256-hash]:[amount]:[expiry-height], on top of the receiver amount.
+They are paid to the receiver if the preimage is revealed on-chain (see reveal-preimage) before the expiry height, otherwise refunded to the sender.
+Senders with a multisig account create the payment with --unsigned, then each key holder signs it with sign-payment --multisig,
+and the signatures are combined with multisign-payment.`,
+		Args: cobra.MinimumNArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			// Create cli helpers
@@ -142,54 +474,473 @@ Specify the channel id, and the total coins to be received by the channel's send
 			if err != nil {
 				return err
 			}
-			senderAmount, err := sdk.ParseCoins(args[1])
-			if err != nil {
-				return err
+			var payout types.Payout
+			for _, arg := range args[1:] {
+				amount, err := sdk.ParseCoins(arg)
+				if err != nil {
+					return err
+				}
+				payout = append(payout, amount)
 			}
-			receiverAmount, err := sdk.ParseCoins(args[2])
-			if err != nil {
-				return err
+			var hashLocks []types.HashLock
+			for _, arg := range viper.GetStringSlice(flagHashLock) {
+				lock, err := parseHashLock(arg)
+				if err != nil {
+					return err
+				}
+				hashLocks = append(hashLocks, lock)
 			}
 
 			// Create an update
 			update := types.Update{
 				ChannelID: channelID,
-				Payout:    types.Payout{senderAmount, receiverAmount},
+				Payout:    payout,
+				Sequence:  viper.GetUint64(flagSequence),
+				HashLocks: hashLocks,
 				// empty signature
 			}
 
+			// Sign the update, with a dedicated channel key if given
+			if !viper.GetBool(flagUnsigned) {
+				name := cliCtx.GetFromName()
+				if signingKey := viper.GetString(flagSigningKey); signingKey != "" {
+					name = signingKey
+				}
+				passphrase, err := keys.GetPassphrase(name)
+				if err != nil {
+					return err
+				}
+				bz := update.GetSignBytes()
+				sig, pubKey, err := txBldr.Keybase().Sign(name, passphrase, bz)
+				if err != nil {
+					return err
+				}
+				update.Sigs = []types.UpdateSignature{{
+					PubKey:          pubKey,
+					CryptoSignature: sig,
+				}}
+			}
+
+			// Write out the update
+			// TODO can this use the cli helpers? Can it be printed to stdOut instead?
+			jsonUpdate, err := codec.MarshalJSONIndent(cdc, update)
+			if err != nil {
+				return err
+			}
+			paymentFile := viper.GetString(flagPaymentFile)
+			err = ioutil.WriteFile(paymentFile, jsonUpdate, 0644)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Written payment out to %v.\n", paymentFile)
+
+			return nil
+		},
+	}
+	cmd.Flags().String(flagPaymentFile, "payment.json", "File name to write the payment into.")
+	cmd.Flags().Uint64(flagSequence, 0, "Sequence number of the payment, only needed for multiparty channels.")
+	cmd.Flags().String(flagSigningKey, "", "Name of the key to sign the payment with, if different from --from.")
+	cmd.Flags().StringSlice(flagHashLock, nil, "Hash locked payment to the receiver as [hex-sha256-hash]:[amount]:[expiry-height], can be repeated.")
+	cmd.Flags().Bool(flagUnsigned, false, "Write out the payment without signing it, for signing by the keys of a multisig account.")
+	return cmd
+}
+
+func GetCmd_SignPayment(cdc *codec.Codec) *cobra.Command {
+	flagPaymentFile := "payment"
+	flagMultisig := "multisig"
+	flagOutputDocument := "output-document"
+
+	cmd := &cobra.Command{
+		Use:   "sign-payment",
+		Short: "add your signature to a payment",
+		Long: `Sign a payment file (json) received from the counterparty, adding the signature to the file.
+Payments in multiparty channels must be signed by the channel's threshold of participants before they can be submitted to the blockchain.
+A payment signed by both participants of any channel can be used to close it immediately with cooperative-close.
+With --multisig the signature is made on behalf of a multisig account and written on its own to --output-document, to be combined with multisign-payment.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Read in the update
+			paymentFile := viper.GetString(flagPaymentFile)
+			bz, err := ioutil.ReadFile(paymentFile)
+			if err != nil {
+				return err
+			}
+			var update types.Update
+			if err = cdc.UnmarshalJSON(bz, &update); err != nil {
+				return err
+			}
+
 			// Sign the update
 			name := cliCtx.GetFromName()
 			passphrase, err := keys.GetPassphrase(name)
 			if err != nil {
 				return err
 			}
-			bz := update.GetSignBytes()
-			sig, pubKey, err := txBldr.Keybase().Sign(name, passphrase, bz)
+			sig, pubKey, err := txBldr.Keybase().Sign(name, passphrase, update.GetSignBytes())
 			if err != nil {
 				return err
 			}
-			update.Sigs = [1]types.UpdateSignature{{
+			signature := types.UpdateSignature{
 				PubKey:          pubKey,
 				CryptoSignature: sig,
-			}}
+			}
+
+			// Write out only the signature if signing for a multisig account
+			if multisigName := viper.GetString(flagMultisig); multisigName != "" {
+				multisigPubKey, err := getMultisigPubKey(txBldr.Keybase(), multisigName)
+				if err != nil {
+					return err
+				}
+				if !containsPubKey(multisigPubKey.PubKeys, pubKey) {
+					return fmt.Errorf("key %s is not part of multisig key %s", name, multisigName)
+				}
+				jsonSignature, err := codec.MarshalJSONIndent(cdc, signature)
+				if err != nil {
+					return err
+				}
+				signatureFile := viper.GetString(flagOutputDocument)
+				err = ioutil.WriteFile(signatureFile, jsonSignature, 0644)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Written signature out to %v.\n", signatureFile)
+				return nil
+			}
+			update.Sigs = append(update.Sigs, signature)
 
 			// Write out the update
-			// TODO can this use the cli helpers? Can it be printed to stdOut instead?
 			jsonUpdate, err := codec.MarshalJSONIndent(cdc, update)
 			if err != nil {
 				return err
 			}
-			paymentFile := viper.GetString(flagPaymentFile)
 			err = ioutil.WriteFile(paymentFile, jsonUpdate, 0644)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Written payment out to %v.\n", paymentFile)
+			fmt.Printf("Added signature to payment in %v.\n", paymentFile)
 
 			return nil
 		},
 	}
-	cmd.Flags().String(flagPaymentFile, "payment.json", "File name to write the payment into.")
+	cmd.Flags().String(flagPaymentFile, "payment.json", "File to read the payment from and write the signed payment to.")
+	cmd.Flags().String(flagMultisig, "", "Name of the multisig key to sign on behalf of, see 'keys add --multisig'.")
+	cmd.Flags().String(flagOutputDocument, "signature.json", "File name to write the signature into when signing with --multisig.")
+	return cmd
+}
+
+func GetCmd_MultisignPayment(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "multisign-payment [payment-file] [multisig-key-name] [signature-file] [[signature-file]...]",
+		Short: "combine signatures from the keys of a multisig account into a payment",
+		Long: `Combine signatures made with sign-payment --multisig into a multisignature, and add it to the payment file.
+The multisig key must be in your keybase (see 'keys add --multisig'), and at least its threshold of signatures are needed for the payment to be valid.`,
+		Args: cobra.MinimumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			// Read in the update
+			paymentFile := args[0]
+			bz, err := ioutil.ReadFile(paymentFile)
+			if err != nil {
+				return err
+			}
+			var update types.Update
+			if err = cdc.UnmarshalJSON(bz, &update); err != nil {
+				return err
+			}
+			multisigPubKey, err := getMultisigPubKey(txBldr.Keybase(), args[1])
+			if err != nil {
+				return err
+			}
+
+			// Read in each signature and add it to the multisignature
+			multisignature := multisig.NewMultisig(len(multisigPubKey.PubKeys))
+			for _, signatureFile := range args[2:] {
+				bz, err := ioutil.ReadFile(signatureFile)
+				if err != nil {
+					return err
+				}
+				var signature types.UpdateSignature
+				if err = cdc.UnmarshalJSON(bz, &signature); err != nil {
+					return err
+				}
+				if signature.PubKey == nil || !signature.PubKey.VerifyBytes(update.GetSignBytes(), signature.CryptoSignature) {
+					return fmt.Errorf("couldn't verify signature in %s", signatureFile)
+				}
+				err = multisignature.AddSignatureFromPubKey(signature.CryptoSignature, signature.PubKey, multisigPubKey.PubKeys)
+				if err != nil {
+					return err
+				}
+			}
+			update.Sigs = append(update.Sigs, types.UpdateSignature{
+				PubKey:          multisigPubKey,
+				CryptoSignature: multisignature.Marshal(),
+			})
+
+			// Write out the update
+			jsonUpdate, err := codec.MarshalJSONIndent(cdc, update)
+			if err != nil {
+				return err
+			}
+			err = ioutil.WriteFile(paymentFile, jsonUpdate, 0644)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Added multisignature to payment in %v.\n", paymentFile)
+
+			return nil
+		},
+	}
+}
+
+func GetCmd_AuthorizeClose(cdc *codec.Codec) *cobra.Command {
+	flagPaymentFile := "payment"
+	flagAuthorizationFile := "filename"
+	flagFee := "fee"
+
+	cmd := &cobra.Command{
+		Use:   "authorize-close",
+		Short: "authorize anyone to close a channel on your behalf with a payment",
+		Long: `Sign an authorization (json) for a payment received from the sender, letting anyone close the channel with it on your (the receiver's) behalf.
+Give the payment and authorization to a relayer, such as a watchtower, to close the channel if the sender tries to close it with an older payment while you are offline (see relay-close).
+The relayer is paid the --fee out of your payout from the channel.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Read in the update
+			bz, err := ioutil.ReadFile(viper.GetString(flagPaymentFile))
+			if err != nil {
+				return err
+			}
+			var update types.Update
+			if err = cdc.UnmarshalJSON(bz, &update); err != nil {
+				return err
+			}
+			fee, err := sdk.ParseCoins(viper.GetString(flagFee))
+			if err != nil {
+				return err
+			}
+
+			// Sign the authorization
+			authorization := types.CloseAuthorization{Fee: fee}
+			name := cliCtx.GetFromName()
+			passphrase, err := keys.GetPassphrase(name)
+			if err != nil {
+				return err
+			}
+			sig, pubKey, err := txBldr.Keybase().Sign(name, passphrase, authorization.GetSignBytes(update))
+			if err != nil {
+				return err
+			}
+			authorization.Sig = types.UpdateSignature{
+				PubKey:          pubKey,
+				CryptoSignature: sig,
+			}
+
+			// Write out the authorization
+			jsonAuthorization, err := codec.MarshalJSONIndent(cdc, authorization)
+			if err != nil {
+				return err
+			}
+			authorizationFile := viper.GetString(flagAuthorizationFile)
+			err = ioutil.WriteFile(authorizationFile, jsonAuthorization, 0644)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Written close authorization out to %v.\n", authorizationFile)
+
+			return nil
+		},
+	}
+	cmd.Flags().String(flagPaymentFile, "payment.json", "File to read the payment from.")
+	cmd.Flags().String(flagAuthorizationFile, "authorization.json", "File name to write the authorization into.")
+	cmd.Flags().String(flagFee, "", "Coins paid to whoever submits the close, out of your payout.")
+	return cmd
+}
+
+func GetCmd_RelayClose(cdc *codec.Codec) *cobra.Command {
+	flagPaymentFile := "payment"
+	flagAuthorizationFile := "authorization"
+
+	cmd := &cobra.Command{
+		Use:   "relay-close",
+		Short: "Close a channel immediately on behalf of its receiver.",
+		Long:  "Submit a payment along with the receiver's close authorization (see authorize-close) to close a unidirectional channel immediately, as if the receiver had submitted it. It can be submitted by anyone, who is paid the authorization's fee.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Get the payment and authorization to be submitted to the blockchain
+			bz, err := ioutil.ReadFile(viper.GetString(flagPaymentFile))
+			if err != nil {
+				return err
+			}
+			var update types.Update
+			if err = cdc.UnmarshalJSON(bz, &update); err != nil {
+				return err
+			}
+			bz, err = ioutil.ReadFile(viper.GetString(flagAuthorizationFile))
+			if err != nil {
+				return err
+			}
+			var authorization types.CloseAuthorization
+			if err = cdc.UnmarshalJSON(bz, &authorization); err != nil {
+				return err
+			}
+
+			// Create msg
+			msg := types.MsgRelayClose{
+				Update:        update,
+				Authorization: authorization,
+				Relayer:       cliCtx.GetFromAddress(),
+			}
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(flagPaymentFile, "payment.json", "File to read the payment from.")
+	cmd.Flags().String(flagAuthorizationFile, "authorization.json", "File to read the receiver's close authorization from.")
 	return cmd
 }
+
+func GetCmd_RevealPreimage(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reveal-preimage [hex-preimage]",
+		Short: "Reveal the secret unlocking hash locked payments",
+		Long:  "Reveal the preimage of a hash lock hash on-chain. Hash locked payments with its hash are paid to their receivers if it is revealed before they expire, including locks in channels that have already closed. It can be sent by anyone.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Parse inputs
+			preimage, err := hex.DecodeString(args[0])
+			if err != nil {
+				return err
+			}
+
+			// Create msg
+			msg := types.MsgRevealPreimage{
+				Submitter: cliCtx.GetFromAddress(),
+				Preimage:  preimage,
+			}
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmd_Reclaim(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reclaim [channel-id]",
+		Short: "Take back the coins left in an expired channel",
+		Long:  "Close a channel that has reached its expiry, returning the coins remaining in it (and any penalty bond) to the sender. No payment is needed. Must be sent from the channel sender's account. Expired channels are also reclaimed automatically at the end of the block.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create cli helpers
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(cdc)
+
+			// Parse inputs
+			channelID, err := types.NewChannelIDFromString(args[0])
+			if err != nil {
+				return err
+			}
+
+			// Create msg
+			msg := types.MsgReclaim{
+				ChannelID: channelID,
+				Sender:    cliCtx.GetFromAddress(),
+			}
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			// Generate tx and maybe sign and broadcast to blockchain
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// parseHashLock parses a hash lock in the format [hex-sha256-hash]:[amount]:[expiry-height].
+func parseHashLock(s string) (types.HashLock, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return types.HashLock{}, fmt.Errorf("hash lock must be formatted as hash:amount:expiry, got %s", s)
+	}
+	hash, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return types.HashLock{}, err
+	}
+	amount, err := sdk.ParseCoins(parts[1])
+	if err != nil {
+		return types.HashLock{}, err
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return types.HashLock{}, err
+	}
+	lock := types.HashLock{Hash: hash, Amount: amount, Expiry: expiry}
+	if !lock.IsValid() {
+		return types.HashLock{}, fmt.Errorf("invalid hash lock %s", s)
+	}
+	return lock, nil
+}
+
+// getMultisigPubKey loads the public key of a multisig key from the keybase.
+func getMultisigPubKey(kb crkeys.Keybase, name string) (multisig.PubKeyMultisigThreshold, error) {
+	info, err := kb.Get(name)
+	if err != nil {
+		return multisig.PubKeyMultisigThreshold{}, err
+	}
+	if info.GetType() != crkeys.TypeMulti {
+		return multisig.PubKeyMultisigThreshold{}, fmt.Errorf("%q must be of type %s: %s", name, crkeys.TypeMulti, info.GetType())
+	}
+	multisigPubKey, ok := info.GetPubKey().(multisig.PubKeyMultisigThreshold)
+	if !ok {
+		return multisig.PubKeyMultisigThreshold{}, fmt.Errorf("%q is not a threshold multisig key", name)
+	}
+	return multisigPubKey, nil
+}
+
+// containsPubKey checks whether a public key is in a list of keys.
+func containsPubKey(pubKeys []crypto.PubKey, pubKey crypto.PubKey) bool {
+	for _, k := range pubKeys {
+		if k.Equals(pubKey) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,56 +1,122 @@
 package rest
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/context"
 	clientrest "github.com/cosmos/cosmos-sdk/client/rest"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/rest"
 	"github.com/gorilla/mux"
+	"github.com/tendermint/tendermint/crypto"
 
 	"github.com/kava-labs/cosmos-paychan/paychan/types"
 )
 
-func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, storeKey string) {
-	//r.HandleFunc("/channels", getChannelsHandlerFn(cliCtx)).Methods("GET")
-	r.HandleFunc("/channels/{id}", getChannelHandlerFn(cliCtx, storeKey)).Methods("GET")
-	r.HandleFunc("/channels/{id}/submitted-update", getUpdateHandlerFn(cliCtx, storeKey)).Methods("GET")
+func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, queryRoute string) {
+	r.HandleFunc("/channels", getChannelsHandlerFn(cliCtx, queryRoute)).Methods("GET")
+	r.HandleFunc("/channels/{id}", getChannelHandlerFn(cliCtx, queryRoute)).Methods("GET")
+	r.HandleFunc("/channels/{id}/submitted-update", getUpdateHandlerFn(cliCtx, queryRoute)).Methods("GET")
+	r.HandleFunc("/escrow", getEscrowHandlerFn(cliCtx, queryRoute)).Methods("GET")
 	r.HandleFunc("/channels", createChannelHandlerFn(cliCtx)).Methods("POST")
-	r.HandleFunc("/channels/{id}/submitted-update", submitUpdateHandlerFn(cliCtx)).Methods("POST") // use simulate flag on post body to verify an update is valid
+	r.HandleFunc("/channels/multiparty", createMultipartyChannelHandlerFn(cliCtx)).Methods("POST") // returns an unsigned tx to be signed by all participants
+	r.HandleFunc("/channels/batch-close", batchCloseHandlerFn(cliCtx)).Methods("POST")
+	r.HandleFunc("/channels/{id}/deposits", depositHandlerFn(cliCtx)).Methods("POST")
+	r.HandleFunc("/channels/{id}/withdrawals", withdrawHandlerFn(cliCtx)).Methods("POST")
+	r.HandleFunc("/channels/{id}/signing-key", rotateSigningKeyHandlerFn(cliCtx)).Methods("POST")
+	r.HandleFunc("/channels/{id}/submitted-update", submitUpdateHandlerFn(cliCtx)).Methods("POST")      // use simulate flag on post body to verify an update is valid
+	r.HandleFunc("/channels/{id}/cooperative-close", cooperativeCloseHandlerFn(cliCtx)).Methods("POST") // the update must be signed by all participants
+	r.HandleFunc("/channels/{id}/relayed-close", relayCloseHandlerFn(cliCtx)).Methods("POST")           // submit a receiver close on their behalf with their close authorization
+	r.HandleFunc("/channels/{id}/reclaim", reclaimHandlerFn(cliCtx)).Methods("POST")
+	r.HandleFunc("/preimages", revealPreimageHandlerFn(cliCtx)).Methods("POST")
 }
 
-func getChannelHandlerFn(cliCtx context.CLIContext, storeKey string) http.HandlerFunc {
+// getChannelsHandlerFn lists open channels, accepting optional "sender", "receiver", "page" and "limit" query parameters.
+func getChannelsHandlerFn(cliCtx context.CLIContext, queryRoute string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse inputs
-		vars := mux.Vars(r)
-		channelID, err := types.NewChannelIDFromString(vars["id"])
+		query := r.URL.Query()
+		page, limit := 1, types.MaxChannelsLimit
+		var err error
+		if p := query.Get("page"); p != "" {
+			page, err = strconv.Atoi(p)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if l := query.Get("limit"); l != "" {
+			limit, err = strconv.Atoi(l)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		var sender, receiver sdk.AccAddress
+		if s := query.Get("sender"); s != "" {
+			sender, err = sdk.AccAddressFromBech32(s)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if rc := query.Get("receiver"); rc != "" {
+			receiver, err = sdk.AccAddressFromBech32(rc)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		bz, err := cliCtx.Codec.MarshalJSON(types.NewQueryChannelsParams(page, limit, sender, receiver))
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// Get channel from store
-		res, err := cliCtx.QueryStore(types.GetChannelKey(channelID), storeKey)
+		// Query the node
+		res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetChannels), bz)
 		if err != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			writeQueryErrorResponse(w, err)
 			return
 		}
-		if len(res) == 0 {
-			rest.WriteErrorResponse(w, http.StatusNotFound, fmt.Sprintf("No channel found with id %v", channelID))
-		}
 
 		// Print response
-		var channel types.Channel
-		if err := cliCtx.Codec.UnmarshalBinaryLengthPrefixed(res, &channel); err != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func getChannelHandlerFn(cliCtx context.CLIContext, queryRoute string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse inputs
+		vars := mux.Vars(r)
+		channelID, err := types.NewChannelIDFromString(vars["id"])
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		bz, err := cliCtx.Codec.MarshalJSON(types.NewQueryChannelParams(channelID))
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Query the node
+		res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetChannel), bz)
+		if err != nil {
+			writeQueryErrorResponse(w, err)
+			return
+		}
+
+		// Print response
 		rest.PostProcessResponse(w, cliCtx, res)
 	}
 }
-func getUpdateHandlerFn(cliCtx context.CLIContext, storeKey string) http.HandlerFunc {
+func getUpdateHandlerFn(cliCtx context.CLIContext, queryRoute string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse inputs
 		vars := mux.Vars(r)
@@ -59,31 +125,63 @@ func getUpdateHandlerFn(cliCtx context.CLIContext, storeKey string) http.Handler
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 			return
 		}
-
-		// Get Update from store
-		res, err := cliCtx.QueryStore(types.GetSubmittedUpdateKey(channelID), storeKey)
+		bz, err := cliCtx.Codec.MarshalJSON(types.NewQueryChannelParams(channelID))
 		if err != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		if len(res) == 0 {
-			rest.WriteErrorResponse(w, http.StatusNotFound, fmt.Sprintf("No submitted update found for channel with id %v", channelID))
+
+		// Query the node
+		res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetSubmittedUpdate), bz)
+		if err != nil {
+			writeQueryErrorResponse(w, err)
+			return
 		}
 
 		// Print response
-		var sUpdate types.SubmittedUpdate
-		if err := cliCtx.Codec.UnmarshalBinaryLengthPrefixed(res, &sUpdate); err != nil {
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func getEscrowHandlerFn(cliCtx context.CLIContext, queryRoute string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Query the node
+		res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryGetEscrow), nil)
+		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+
+		// Print response
 		rest.PostProcessResponse(w, cliCtx, res)
 	}
 }
 
+// writeQueryErrorResponse writes the error from a failed query, using status 404 if the channel or submitted update doesn't exist.
+// The error body is the ABCI log, which includes the codespace and code so clients can tell errors apart.
+func writeQueryErrorResponse(w http.ResponseWriter, err error) {
+	var abciErr struct {
+		Codespace sdk.CodespaceType `json:"codespace"`
+		Code      sdk.CodeType      `json:"code"`
+	}
+	status := http.StatusInternalServerError
+	if json.Unmarshal([]byte(err.Error()), &abciErr) == nil {
+		switch abciErr.Code {
+		case types.CodeUnknownChannel, types.CodeUnknownSubmittedUpdate:
+			status = http.StatusNotFound
+		}
+	}
+	rest.WriteErrorResponse(w, status, err.Error())
+}
+
 type CreateChannelRequest struct {
-	BaseReq  rest.BaseReq   `json:"base_req"`
-	Receiver sdk.AccAddress `json:"receiver"` // in bech32
-	Coins    sdk.Coins      `json:"coins"`
+	BaseReq       rest.BaseReq   `json:"base_req"`
+	Receiver      sdk.AccAddress `json:"receiver"` // in bech32
+	Coins         sdk.Coins      `json:"coins"`
+	DisputePeriod time.Duration  `json:"dispute_period"` // optional, in nanoseconds
+	SigningPubKey string         `json:"signing_pubkey"` // optional, in bech32
+	PenaltyBond   sdk.Coins      `json:"penalty_bond"`   // optional
+	Expiry        time.Time      `json:"expiry"`         // optional
 }
 
 func createChannelHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
@@ -103,10 +201,155 @@ func createChannelHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		var signingPubKey crypto.PubKey
+		if req.SigningPubKey != "" {
+			signingPubKey, err = sdk.GetAccPubKeyBech32(req.SigningPubKey)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
 		// Create the msg
 		msg := types.MsgCreate{
-			Participants: [2]sdk.AccAddress{fromAddr, req.Receiver},
-			Coins:        req.Coins,
+			Participants:  [2]sdk.AccAddress{fromAddr, req.Receiver},
+			Coins:         req.Coins,
+			DisputePeriod: req.DisputePeriod,
+			SigningPubKey: signingPubKey,
+			PenaltyBond:   req.PenaltyBond,
+			Expiry:        req.Expiry,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type CreateMultipartyChannelRequest struct {
+	BaseReq       rest.BaseReq     `json:"base_req"`
+	Participants  []sdk.AccAddress `json:"participants"`   // in bech32
+	Deposits      types.Payout     `json:"deposits"`       // coins from each participant, in the same order
+	SigThreshold  uint64           `json:"sig_threshold"`  // optional, defaults to all participants
+	DisputePeriod time.Duration    `json:"dispute_period"` // optional, in nanoseconds
+}
+
+func createMultipartyChannelHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get args from post body
+		var req CreateMultipartyChannelRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgCreateMultiparty{
+			Participants:  req.Participants,
+			Deposits:      req.Deposits,
+			SigThreshold:  req.SigThreshold,
+			DisputePeriod: req.DisputePeriod,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type DepositRequest struct {
+	BaseReq rest.BaseReq `json:"base_req"`
+	Coins   sdk.Coins    `json:"coins"`
+}
+
+func depositHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse inputs
+		vars := mux.Vars(r)
+		channelID, err := types.NewChannelIDFromString(vars["id"])
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Get args from post body
+		var req DepositRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		fromAddr, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgDeposit{
+			ChannelID: channelID,
+			Depositor: fromAddr,
+			Coins:     req.Coins,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type RotateSigningKeyRequest struct {
+	BaseReq          rest.BaseReq `json:"base_req"`
+	NewSigningPubKey string       `json:"new_signing_pubkey"` // in bech32
+}
+
+func rotateSigningKeyHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse inputs
+		vars := mux.Vars(r)
+		channelID, err := types.NewChannelIDFromString(vars["id"])
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Get args from post body
+		var req RotateSigningKeyRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		fromAddr, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		pubKey, err := sdk.GetAccPubKeyBech32(req.NewSigningPubKey)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgRotateSigningKey{
+			ChannelID:        channelID,
+			Sender:           fromAddr,
+			NewSigningPubKey: pubKey,
 		}
 		if err := msg.ValidateBasic(); err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -154,3 +397,238 @@ func submitUpdateHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
 		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
 	}
 }
+
+type CooperativeCloseRequest struct {
+	BaseReq rest.BaseReq `json:"base_req"`
+	Update  types.Update `json:"update"`
+}
+
+func cooperativeCloseHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get args from post body
+		var req CooperativeCloseRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		submitter, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgCooperativeClose{
+			Update:    req.Update,
+			Submitter: submitter,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type BatchCloseRequest struct {
+	BaseReq rest.BaseReq   `json:"base_req"`
+	Updates []types.Update `json:"updates"`
+}
+
+func batchCloseHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get args from post body
+		var req BatchCloseRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		receiver, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgBatchClose{
+			Updates:  req.Updates,
+			Receiver: receiver,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type RelayCloseRequest struct {
+	BaseReq       rest.BaseReq             `json:"base_req"`
+	Update        types.Update             `json:"update"`
+	Authorization types.CloseAuthorization `json:"authorization"`
+}
+
+func relayCloseHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get args from post body
+		var req RelayCloseRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		relayer, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgRelayClose{
+			Update:        req.Update,
+			Authorization: req.Authorization,
+			Relayer:       relayer,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type WithdrawRequest struct {
+	BaseReq rest.BaseReq `json:"base_req"`
+	Update  types.Update `json:"update"`
+}
+
+func withdrawHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get args from post body
+		var req WithdrawRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		receiver, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgWithdraw{
+			Update:   req.Update,
+			Receiver: receiver,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type RevealPreimageRequest struct {
+	BaseReq  rest.BaseReq `json:"base_req"`
+	Preimage string       `json:"preimage"` // in hex
+}
+
+func revealPreimageHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get args from post body
+		var req RevealPreimageRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		submitter, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		preimage, err := hex.DecodeString(req.Preimage)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgRevealPreimage{
+			Submitter: submitter,
+			Preimage:  preimage,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type ReclaimRequest struct {
+	BaseReq rest.BaseReq `json:"base_req"`
+}
+
+func reclaimHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse inputs
+		vars := mux.Vars(r)
+		channelID, err := types.NewChannelIDFromString(vars["id"])
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Get args from post body
+		var req ReclaimRequest
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			return
+		}
+		req.BaseReq = req.BaseReq.Sanitize()
+		if !req.BaseReq.ValidateBasic(w) {
+			return
+		}
+		fromAddr, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Create the msg
+		msg := types.MsgReclaim{
+			ChannelID: channelID,
+			Sender:    fromAddr,
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Generate tx and write response
+		clientrest.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
@@ -3,6 +3,20 @@ Package paychan provides unidirectional payment channels for cosmos SDK based bl
 
 This module implements simple but feature complete unidirectional payment channels.
 Channels can be opened by a sender and closed immediately by the receiver, or by the sender subject to a dispute period.
-There are no top-ups or partial withdrawals (yet). Channels support multiple currencies.
+Senders can top up open channels and receivers can make partial withdrawals without closing them. Channels support multiple currencies.
+Senders can sign updates with a dedicated channel key rather than the account key holding their funds, and can be multisig accounts.
+Senders can lock up a penalty bond, a share of which goes to the receiver if the sender tries to close with a stale update.
+Channels can have an expiry, after which the receiver can no longer close them and the remaining coins are returned to the sender.
+
+Multiparty channels are funded by two or more participants (bidirectional channels have two) and closed by any after a dispute period,
+during which the others can replace the submitted update with one with a higher sequence number. Updates need a threshold of participant signatures.
+
+Any channel can be closed immediately with an update signed by all its participants.
+Receivers can sign an authorization letting anyone (eg a watchtower) close on their behalf, paying the relayer a fee out of their payout.
+
+Updates in unidirectional channels can include hash locks, paying an amount to the receiver only if the preimage of a hash is revealed on-chain
+before an expiry height, and refunding it to the sender otherwise. Locks that haven't expired when the channel closes are settled once they do.
+
+Coins locked in channels are held in escrow by a module account, so they remain part of the total supply.
 */
 package paychan
@@ -6,34 +6,52 @@ import (
 	"github.com/kava-labs/cosmos-paychan/paychan/types"
 )
 
-// EndBlocker closes channels that have past their execution time.
-// It runs at the end of every block, comparing submitted updates against the current block height.
+// EndBlocker closes channels that have past their execution time, returns the coins in expired channels to their senders,
+// then settles pending hash locks that have expired.
+// It runs at the end of every block, only loading the submitted updates, channels and locks that are due.
 func EndBlocker(ctx sdk.Context, k Keeper) sdk.Tags {
-	var err sdk.Error
-	var channelTags sdk.Tags
 	tags := sdk.EmptyTags()
 
-	// Iterate through submittedUpdatesQueue
-	// TODO optimise by using store iterator
-	q := k.getSubmittedUpdatesQueue(ctx)
-	var sUpdate types.SubmittedUpdate
-	var found bool
+	// Collect the matured updates first as the store can't be modified while iterating over it
+	var channelIDs []types.ChannelID
+	iter := k.submittedUpdatesQueueIterator(ctx, ctx.BlockHeader().Time)
+	for ; iter.Valid(); iter.Next() {
+		var channelID types.ChannelID
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &channelID)
+		channelIDs = append(channelIDs, channelID)
+	}
+	iter.Close()
 
-	for _, id := range q {
-		// close the channel if the update has reached its execution time.
-		// Using >= in case some are somehow missed.
-		sUpdate, found = k.getSubmittedUpdate(ctx, id)
+	for _, id := range channelIDs {
+		sUpdate, found := k.getSubmittedUpdate(ctx, id)
 		if !found {
 			panic("can't find element in queue that should exist")
 		}
-		if ctx.BlockHeight() >= sUpdate.ExecutionTime {
-			k.removeFromSubmittedUpdatesQueue(ctx, sUpdate.ChannelID)
-			channelTags, err = k.closeChannel(ctx, sUpdate.Update)
-			if err != nil {
-				panic(err)
-			}
-			tags.AppendTags(channelTags)
+		k.removeFromSubmittedUpdatesQueue(ctx, sUpdate.ChannelID)
+		channelTags, err := k.closeChannel(ctx, sUpdate.Update)
+		if err != nil {
+			panic(err)
 		}
+		tags = tags.AppendTags(channelTags.AppendTag(types.TagCloseType, types.CloseTypeDisputeExpired))
+	}
+
+	// Channels still open at their expiry are reclaimed for the sender
+	channelIDs = nil
+	iter = k.channelExpiryQueueIterator(ctx, ctx.BlockHeader().Time)
+	for ; iter.Valid(); iter.Next() {
+		var channelID types.ChannelID
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &channelID)
+		channelIDs = append(channelIDs, channelID)
 	}
-	return tags
+	iter.Close()
+
+	for _, id := range channelIDs {
+		channel, found := k.getChannel(ctx, id)
+		if !found {
+			panic("can't find element in queue that should exist")
+		}
+		tags = tags.AppendTags(k.reclaimChannel(ctx, channel))
+	}
+
+	return tags.AppendTags(k.settleExpiredHashLocks(ctx))
 }
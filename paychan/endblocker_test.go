@@ -1,7 +1,9 @@
 package paychan
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/assert"
@@ -10,11 +12,10 @@ import (
 )
 
 func TestEndBlocker(t *testing.T) {
-	// TODO test that endBlocker doesn't close channels before the execution time
-
 	// SETUP
 	accountSeeds := []string{"senderSeed", "receiverSeed"}
-	ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	ctx, bankKeeper, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	executionTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
 	sender := addrs[0]
 	receiver := addrs[1]
 	coins := sdk.Coins{sdk.NewInt64Coin("usd", 10)}
@@ -23,10 +24,12 @@ func TestEndBlocker(t *testing.T) {
 	channelID := types.ChannelID(0) // should be 0 as first channel
 	channel := types.Channel{
 		ID:           channelID,
-		Participants: [2]sdk.AccAddress{sender, receiver},
+		Participants: []sdk.AccAddress{sender, receiver},
 		Coins:        coins,
 	}
 	channelKeeper.setChannel(ctx, channel)
+	// lock the channel coins in the module account
+	bankKeeper.AddCoins(ctx, types.ModuleAddress, coins)
 
 	// create closing update and submittedUpdate
 	payout := types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}
@@ -36,26 +39,129 @@ func TestEndBlocker(t *testing.T) {
 	}
 	sUpdate := types.SubmittedUpdate{
 		Update:        update,
-		ExecutionTime: 0, // current blocktime
+		ExecutionTime: executionTime,
 	}
-	// Set empty submittedUpdatesQueue TODO work out proper genesis initialisation
-	channelKeeper.setSubmittedUpdatesQueue(ctx, types.SubmittedUpdatesQueue{})
 	// flag channel for closure
 	channelKeeper.addToSubmittedUpdatesQueue(ctx, sUpdate)
 
 	// ACTION
-	EndBlocker(ctx, channelKeeper)
+	// run before the execution time
+	EndBlocker(ctx.WithBlockTime(executionTime.Add(-time.Second)), channelKeeper)
+
+	// CHECK RESULTS
+	// check channel and submittedUpdate still exist
+	_, found := channelKeeper.getChannel(ctx, channelID)
+	assert.True(t, found)
+	_, found = channelKeeper.getSubmittedUpdate(ctx, channelID)
+	assert.True(t, found)
+
+	// ACTION
+	// run at the execution time
+	EndBlocker(ctx.WithBlockTime(executionTime), channelKeeper)
 
 	// CHECK RESULTS
 	// ideally just check if keeper.channelClose was called, but can't
 	// writing endBlocker to accept an interface of which keeper is implementation would make this possible
 	// check channel is gone
-	_, found := channelKeeper.getChannel(ctx, channelID)
+	_, found = channelKeeper.getChannel(ctx, channelID)
 	assert.False(t, found)
-	// check queue is empty, NOTE: due to encoding, an empty queue (underneath just an int slice) will be decoded as nil slice rather than an empty slice
-	suq := channelKeeper.getSubmittedUpdatesQueue(ctx)
-	assert.Equal(t, types.SubmittedUpdatesQueue(nil), suq)
+	// check queue is empty
+	numQueued := 0
+	channelKeeper.iterateSubmittedUpdatesQueue(ctx, func(types.ChannelID) bool {
+		numQueued++
+		return false
+	})
+	assert.Equal(t, 0, numQueued)
 	// check submittedUpdate is gone
 	_, found = channelKeeper.getSubmittedUpdate(ctx, channelID)
 	assert.False(t, found)
 }
+
+func TestEndBlockerChannelExpiry(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, bankKeeper, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	expiry := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	sender := addrs[0]
+	receiver := addrs[1]
+	coins := sdk.Coins{sdk.NewInt64Coin("usd", 10)}
+
+	// create a channel that has had some coins withdrawn by the receiver
+	channel := types.Channel{
+		ID:           0,
+		Participants: []sdk.AccAddress{sender, receiver},
+		Coins:        coins,
+		Withdrawn:    sdk.Coins{sdk.NewInt64Coin("usd", 4)},
+		Expiry:       expiry,
+	}
+	channelKeeper.setChannel(ctx, channel)
+	bankKeeper.AddCoins(ctx, types.ModuleAddress, coins)
+
+	// ACTION
+	// run before the expiry
+	EndBlocker(ctx.WithBlockTime(expiry.Add(-time.Second)), channelKeeper)
+
+	// CHECK RESULTS
+	_, found := channelKeeper.getChannel(ctx, channel.ID)
+	assert.True(t, found)
+
+	// ACTION
+	// run at the expiry
+	tags := EndBlocker(ctx.WithBlockTime(expiry), channelKeeper)
+
+	// CHECK RESULTS
+	// channel is gone and the remaining coins returned to the sender
+	_, found = channelKeeper.getChannel(ctx, channel.ID)
+	assert.False(t, found)
+	assert.Equal(t, sdk.Coins{sdk.NewInt64Coin("usd", 1010)}, bankKeeper.GetCoins(ctx, sender))
+	assert.Equal(t, sdk.Coins{sdk.NewInt64Coin("usd", 1000)}, bankKeeper.GetCoins(ctx, receiver))
+	assert.Contains(t, tags, sdk.MakeTag(types.TagCloseType, types.CloseTypeExpired))
+	assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+}
+
+// The cost of the EndBlocker and of submitting or removing a close should not depend on the number of closes pending.
+
+func BenchmarkEndBlocker(b *testing.B) {
+	for _, numPending := range []int{10, 100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%dPending", numPending), func(b *testing.B) {
+			ctx, channelKeeper := setupPendingCloses(numPending)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				EndBlocker(ctx, channelKeeper)
+			}
+		})
+	}
+}
+
+func BenchmarkSubmittedUpdatesQueue(b *testing.B) {
+	for _, numPending := range []int{10, 100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%dPending", numPending), func(b *testing.B) {
+			ctx, channelKeeper := setupPendingCloses(numPending)
+			sUpdate := types.SubmittedUpdate{
+				Update:        types.Update{ChannelID: types.ChannelID(numPending)},
+				ExecutionTime: ctx.BlockHeader().Time.Add(time.Hour),
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				channelKeeper.addToSubmittedUpdatesQueue(ctx, sUpdate)
+				channelKeeper.removeFromSubmittedUpdatesQueue(ctx, sUpdate.ChannelID)
+			}
+		})
+	}
+}
+
+// setupPendingCloses queues submitted updates for the given number of channels, none of which are due to execute.
+func setupPendingCloses(numPending int) (sdk.Context, Keeper) {
+	ctx, _, channelKeeper, _, _, _, _ := createMockApp([]string{"senderSeed", "receiverSeed"})
+	ctx = ctx.WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	payout := types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}
+	for i := 0; i < numPending; i++ {
+		channelKeeper.addToSubmittedUpdatesQueue(ctx, types.SubmittedUpdate{
+			Update:        types.Update{ChannelID: types.ChannelID(i), Payout: payout},
+			ExecutionTime: ctx.BlockHeader().Time.Add(time.Duration(i+1) * time.Second),
+		})
+	}
+	// write through to the underlying store as happens when a block is committed, otherwise iterating is dominated by sorting the write cache
+	ctx.MultiStore().(sdk.CacheMultiStore).Write()
+	return ctx, channelKeeper
+}
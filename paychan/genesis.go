@@ -0,0 +1,59 @@
+package paychan
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+// InitGenesis loads params, channels, submitted updates, the channel id counter and hash lock state into the store.
+// The genesis state is assumed to have been validated with ValidateGenesis.
+func InitGenesis(ctx sdk.Context, k Keeper, data types.GenesisState) {
+	k.SetParams(ctx, data.Params)
+	for _, channel := range data.Channels {
+		k.setChannel(ctx, channel)
+	}
+	for _, sUpdate := range data.SubmittedUpdates {
+		k.addToSubmittedUpdatesQueue(ctx, sUpdate)
+	}
+	k.setNextChannelID(ctx, data.NextChannelID)
+	for _, lock := range data.PendingHashLocks {
+		k.setPendingHashLock(ctx, lock)
+	}
+	for _, revealed := range data.RevealedPreimages {
+		k.setPreimageRevealHeight(ctx, revealed.Hash, revealed.Height)
+	}
+}
+
+// ExportGenesis returns a GenesisState containing all open channels, pending closes and hash lock state.
+func ExportGenesis(ctx sdk.Context, k Keeper) types.GenesisState {
+	channels := []types.Channel{}
+	k.iterateChannels(ctx, func(channel types.Channel) bool {
+		channels = append(channels, channel)
+		return false
+	})
+
+	// Export in execution order
+	sUpdates := []types.SubmittedUpdate{}
+	k.iterateSubmittedUpdatesQueue(ctx, func(channelID types.ChannelID) bool {
+		sUpdate, found := k.getSubmittedUpdate(ctx, channelID)
+		if !found {
+			panic("can't find element in queue that should exist")
+		}
+		sUpdates = append(sUpdates, sUpdate)
+		return false
+	})
+
+	pendingHashLocks := []types.PendingHashLock{}
+	k.iteratePendingHashLocks(ctx, func(lock types.PendingHashLock) bool {
+		pendingHashLocks = append(pendingHashLocks, lock)
+		return false
+	})
+	revealedPreimages := []types.RevealedPreimage{}
+	k.iterateRevealedPreimages(ctx, func(revealed types.RevealedPreimage) bool {
+		revealedPreimages = append(revealedPreimages, revealed)
+		return false
+	})
+
+	return types.NewGenesisState(k.GetParams(ctx), channels, sUpdates, k.getNextChannelID(ctx), pendingHashLocks, revealedPreimages)
+}
@@ -0,0 +1,87 @@
+package paychan
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+func TestGenesis(t *testing.T) {
+	t.Run("DefaultGenesis", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, _, channelKeeper, _, _, _, _ := createMockApp(accountSeeds)
+
+		// ACTION
+		InitGenesis(ctx, channelKeeper, types.DefaultGenesisState())
+
+		// CHECK RESULTS
+		assert.Equal(t, types.DefaultGenesisState(), ExportGenesis(ctx, channelKeeper))
+	})
+
+	t.Run("ExportImport", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		const (
+			senderAccountIndex   int = 0
+			receiverAccountIndex int = 1
+		)
+		ctx, _, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+		sender := addrs[senderAccountIndex]
+		receiver := addrs[receiverAccountIndex]
+
+		// create some channels, closing one and initiating the close of another
+		for i := 0; i < 3; i++ {
+			_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+			require.NoError(t, err)
+		}
+		for _, id := range []types.ChannelID{1, 2} {
+			update := signedUpdate(id, usdPayout(3, 7), 0, privKeys[senderAccountIndex])
+			_, err := channelKeeper.InitCloseChannelBySender(ctx, update)
+			require.NoError(t, err)
+			if id == 1 {
+				_, err = channelKeeper.CloseChannelByReceiver(ctx, update)
+				require.NoError(t, err)
+			}
+		}
+
+		// reveal a preimage and leave a hash lock pending from a closed channel
+		_, err := channelKeeper.RevealPreimage(ctx, []byte("secret"))
+		require.NoError(t, err)
+		hash := sha256.Sum256([]byte("secret"))
+		channelKeeper.setPendingHashLock(ctx, types.PendingHashLock{
+			HashLock:  types.HashLock{Hash: hash[:], Amount: usd(1), Expiry: 100},
+			ChannelID: 1,
+			Sender:    sender,
+			Receiver:  receiver,
+		})
+
+		// ACTION
+		exported := ExportGenesis(ctx, channelKeeper)
+		// pass through json as would happen during a chain upgrade
+		bz := types.ModuleCdc.MustMarshalJSON(exported)
+		var imported types.GenesisState
+		types.ModuleCdc.MustUnmarshalJSON(bz, &imported)
+		require.NoError(t, types.ValidateGenesis(imported))
+
+		newCtx, _, newChannelKeeper, _, _, _, _ := createMockApp(accountSeeds)
+		InitGenesis(newCtx, newChannelKeeper, imported)
+
+		// CHECK RESULTS
+		assert.Len(t, exported.Channels, 2)
+		assert.Len(t, exported.SubmittedUpdates, 1)
+		assert.Equal(t, types.ChannelID(3), exported.NextChannelID)
+		assert.Len(t, exported.PendingHashLocks, 1)
+		assert.Len(t, exported.RevealedPreimages, 1)
+		// state round trips
+		assert.Equal(t, exported, ExportGenesis(newCtx, newChannelKeeper))
+		assert.Nil(t, SubmittedUpdatesQueueInvariant(newChannelKeeper)(newCtx))
+		// new channels don't reuse old ids
+		assert.Equal(t, types.ChannelID(3), newChannelKeeper.getNewChannelID(newCtx))
+	})
+}
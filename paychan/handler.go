@@ -15,8 +15,26 @@ func NewHandler(k Keeper) sdk.Handler {
 		switch msg := msg.(type) {
 		case types.MsgCreate:
 			return handleMsgCreate(ctx, k, msg)
+		case types.MsgCreateMultiparty:
+			return handleMsgCreateMultiparty(ctx, k, msg)
 		case types.MsgSubmitUpdate:
 			return handleMsgSubmitUpdate(ctx, k, msg)
+		case types.MsgDeposit:
+			return handleMsgDeposit(ctx, k, msg)
+		case types.MsgWithdraw:
+			return handleMsgWithdraw(ctx, k, msg)
+		case types.MsgCooperativeClose:
+			return handleMsgCooperativeClose(ctx, k, msg)
+		case types.MsgRotateSigningKey:
+			return handleMsgRotateSigningKey(ctx, k, msg)
+		case types.MsgRevealPreimage:
+			return handleMsgRevealPreimage(ctx, k, msg)
+		case types.MsgReclaim:
+			return handleMsgReclaim(ctx, k, msg)
+		case types.MsgRelayClose:
+			return handleMsgRelayClose(ctx, k, msg)
+		case types.MsgBatchClose:
+			return handleMsgBatchClose(ctx, k, msg)
 		default:
 			errMsg := fmt.Sprintf("unrecognized %s message type: %T", ModuleName, msg)
 			return sdk.ErrUnknownRequest(errMsg).Result()
@@ -27,7 +45,19 @@ func NewHandler(k Keeper) sdk.Handler {
 // Handle MsgCreate
 // Leaves validation to the keeper methods.
 func handleMsgCreate(ctx sdk.Context, k Keeper, msg types.MsgCreate) sdk.Result {
-	tags, err := k.CreateChannel(ctx, msg.Participants[0], msg.Participants[len(msg.Participants)-1], msg.Coins)
+	tags, err := k.CreateChannel(ctx, msg.Participants[0], msg.Participants[len(msg.Participants)-1], msg.Coins, msg.DisputePeriod, msg.SigningPubKey, msg.PenaltyBond, msg.Expiry)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgCreateMultiparty
+// Leaves validation to the keeper methods.
+func handleMsgCreateMultiparty(ctx sdk.Context, k Keeper, msg types.MsgCreateMultiparty) sdk.Result {
+	tags, err := k.CreateMultipartyChannel(ctx, msg.Participants, msg.Deposits, msg.SigThreshold, msg.DisputePeriod)
 	if err != nil {
 		return err.Result()
 	}
@@ -43,18 +73,21 @@ func handleMsgSubmitUpdate(ctx sdk.Context, k Keeper, msg types.MsgSubmitUpdate)
 
 	channel, found := k.getChannel(ctx, msg.Update.ChannelID)
 	if !found {
-		return sdk.ErrInternal("channel not found").Result()
+		return types.ErrUnknownChannel(k.codespace, msg.Update.ChannelID).Result()
 	}
 	participants := channel.Participants
 
-	// if only sender signed
-	if msg.Submitter.Equals(participants[0]) {
+	// in multiparty channels any participant can start a dispute
+	if channel.Multiparty && channel.IsParticipant(msg.Submitter) {
+		tags, err = k.InitCloseChannelBySender(ctx, msg.Update)
+		// if only sender signed
+	} else if msg.Submitter.Equals(participants[0]) {
 		tags, err = k.InitCloseChannelBySender(ctx, msg.Update)
 		// else if receiver signed
 	} else if msg.Submitter.Equals(participants[len(participants)-1]) {
 		tags, err = k.CloseChannelByReceiver(ctx, msg.Update)
 	} else {
-		return sdk.ErrInternal("update submitter does not match channel sender or receiver").Result()
+		return types.ErrWrongSubmitter(k.codespace, "update submitter does not match channel sender or receiver").Result()
 	}
 
 	if err != nil {
@@ -65,3 +98,105 @@ func handleMsgSubmitUpdate(ctx sdk.Context, k Keeper, msg types.MsgSubmitUpdate)
 		Tags: tags,
 	}
 }
+
+// Handle MsgDeposit
+// Leaves validation to the keeper methods.
+func handleMsgDeposit(ctx sdk.Context, k Keeper, msg types.MsgDeposit) sdk.Result {
+	tags, err := k.Deposit(ctx, msg.ChannelID, msg.Depositor, msg.Coins)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgWithdraw
+func handleMsgWithdraw(ctx sdk.Context, k Keeper, msg types.MsgWithdraw) sdk.Result {
+	channel, found := k.getChannel(ctx, msg.Update.ChannelID)
+	if !found {
+		return types.ErrUnknownChannel(k.codespace, msg.Update.ChannelID).Result()
+	}
+	participants := channel.Participants
+	if !msg.Receiver.Equals(participants[len(participants)-1]) {
+		return types.ErrWrongSubmitter(k.codespace, "withdrawer does not match channel receiver").Result()
+	}
+
+	tags, err := k.Withdraw(ctx, msg.Update)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgCooperativeClose
+// The update must be signed by all participants so anyone can submit it. Leaves validation to the keeper methods.
+func handleMsgCooperativeClose(ctx sdk.Context, k Keeper, msg types.MsgCooperativeClose) sdk.Result {
+	tags, err := k.CooperativeCloseChannel(ctx, msg.Update)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgRotateSigningKey
+// Leaves validation to the keeper methods.
+func handleMsgRotateSigningKey(ctx sdk.Context, k Keeper, msg types.MsgRotateSigningKey) sdk.Result {
+	tags, err := k.RotateSigningKey(ctx, msg.ChannelID, msg.Sender, msg.NewSigningPubKey)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgRevealPreimage
+// Leaves validation to the keeper methods.
+func handleMsgRevealPreimage(ctx sdk.Context, k Keeper, msg types.MsgRevealPreimage) sdk.Result {
+	tags, err := k.RevealPreimage(ctx, msg.Preimage)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgReclaim
+// Leaves validation to the keeper methods.
+func handleMsgReclaim(ctx sdk.Context, k Keeper, msg types.MsgReclaim) sdk.Result {
+	tags, err := k.ReclaimExpiredChannel(ctx, msg.ChannelID, msg.Sender)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgRelayClose
+// The receiver's authorization lets anyone submit it. Leaves validation to the keeper methods.
+func handleMsgRelayClose(ctx sdk.Context, k Keeper, msg types.MsgRelayClose) sdk.Result {
+	tags, err := k.RelayCloseChannelByReceiver(ctx, msg.Update, msg.Authorization, msg.Relayer)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: tags,
+	}
+}
+
+// Handle MsgBatchClose
+// Updates that fail to close don't fail the tx, the outcome for each is returned in the result data. Leaves validation to the keeper methods.
+func handleMsgBatchClose(ctx sdk.Context, k Keeper, msg types.MsgBatchClose) sdk.Result {
+	tags, results := k.BatchCloseChannelsByReceiver(ctx, msg.Updates, msg.Receiver)
+	return sdk.Result{
+		Data: k.cdc.MustMarshalJSON(results),
+		Tags: tags,
+	}
+}
@@ -0,0 +1,202 @@
+package paychan
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+// RegisterInvariants registers the paychan invariants with the crisis module.
+func RegisterInvariants(ir sdk.InvariantRouter, k Keeper) {
+	ir.RegisterRoute(ModuleName, "submitted-updates", SubmittedUpdatesInvariant(k))
+	ir.RegisterRoute(ModuleName, "submitted-updates-queue", SubmittedUpdatesQueueInvariant(k))
+	ir.RegisterRoute(ModuleName, "channel-ids", ChannelIDsInvariant(k))
+	ir.RegisterRoute(ModuleName, "channel-indexes", ChannelIndexesInvariant(k))
+	ir.RegisterRoute(ModuleName, "channel-expiry-queue", ChannelExpiryQueueInvariant(k))
+	ir.RegisterRoute(ModuleName, "locked-coins", LockedCoinsInvariant(k))
+}
+
+// AllInvariants runs all the paychan invariants, returning the first error.
+func AllInvariants(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) error {
+		for _, invariant := range []sdk.Invariant{
+			SubmittedUpdatesInvariant(k),
+			SubmittedUpdatesQueueInvariant(k),
+			ChannelIDsInvariant(k),
+			ChannelIndexesInvariant(k),
+			ChannelExpiryQueueInvariant(k),
+			LockedCoinsInvariant(k),
+		} {
+			if err := invariant(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// SubmittedUpdatesInvariant checks every submitted update is for a channel that exists.
+func SubmittedUpdatesInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) error {
+		var err error
+		k.iterateSubmittedUpdates(ctx, func(sUpdate types.SubmittedUpdate) bool {
+			if _, found := k.getChannel(ctx, sUpdate.ChannelID); !found {
+				err = fmt.Errorf("submitted update for channel %d has no matching channel", sUpdate.ChannelID)
+				return true
+			}
+			return false
+		})
+		return err
+	}
+}
+
+// SubmittedUpdatesQueueInvariant checks the submitted updates queue has exactly one entry for each stored submitted update, at its execution time.
+func SubmittedUpdatesQueueInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) error {
+		store := ctx.KVStore(k.storeKey)
+		numQueued := 0
+		var err error
+		k.iterateSubmittedUpdatesQueue(ctx, func(channelID types.ChannelID) bool {
+			numQueued++
+			sUpdate, found := k.getSubmittedUpdate(ctx, channelID)
+			if !found {
+				err = fmt.Errorf("channel %d is in the submitted updates queue but has no submitted update", channelID)
+				return true
+			}
+			if !store.Has(types.GetSubmittedUpdatesQueueKey(sUpdate.ExecutionTime, channelID)) {
+				err = fmt.Errorf("channel %d is in the submitted updates queue at the wrong execution time", channelID)
+				return true
+			}
+			return false
+		})
+		if err != nil {
+			return err
+		}
+
+		numSUpdates := 0
+		k.iterateSubmittedUpdates(ctx, func(sUpdate types.SubmittedUpdate) bool {
+			numSUpdates++
+			return false
+		})
+		if numQueued != numSUpdates {
+			return fmt.Errorf("submitted updates queue has %d entries but there are %d submitted updates", numQueued, numSUpdates)
+		}
+		return nil
+	}
+}
+
+// ChannelIDsInvariant checks no channel has an ID that hasn't been issued yet.
+func ChannelIDsInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) error {
+		nextID := k.getNextChannelID(ctx)
+		var err error
+		k.iterateChannels(ctx, func(channel types.Channel) bool {
+			if channel.ID >= nextID {
+				err = fmt.Errorf("channel id %d hasn't been issued yet, next channel id is %d", channel.ID, nextID)
+				return true
+			}
+			return false
+		})
+		return err
+	}
+}
+
+// ChannelIndexesInvariant checks the sender and receiver indexes have exactly one entry for each sender and receiver of every channel.
+func ChannelIndexesInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) error {
+		store := ctx.KVStore(k.storeKey)
+		numExpected := 0
+		var err error
+		k.iterateChannels(ctx, func(channel types.Channel) bool {
+			for _, sender := range channel.Senders() {
+				numExpected++
+				if !store.Has(types.GetChannelsBySenderKey(sender, channel.ID)) {
+					err = fmt.Errorf("channel %d is missing from the index of channels by sender %s", channel.ID, sender)
+					return true
+				}
+			}
+			for _, receiver := range channel.Receivers() {
+				numExpected++
+				if !store.Has(types.GetChannelsByReceiverKey(receiver, channel.ID)) {
+					err = fmt.Errorf("channel %d is missing from the index of channels by receiver %s", channel.ID, receiver)
+					return true
+				}
+			}
+			return false
+		})
+		if err != nil {
+			return err
+		}
+
+		numIndexed := 0
+		for _, prefix := range [][]byte{types.ChannelsBySenderKeyPrefix, types.ChannelsByReceiverKeyPrefix} {
+			iter := sdk.KVStorePrefixIterator(store, prefix)
+			for ; iter.Valid(); iter.Next() {
+				numIndexed++
+			}
+			iter.Close()
+		}
+		if numIndexed != numExpected {
+			return fmt.Errorf("channel indexes have %d entries but channels have %d senders and receivers", numIndexed, numExpected)
+		}
+		return nil
+	}
+}
+
+// ChannelExpiryQueueInvariant checks the channel expiry queue has exactly one entry for each channel with an expiry, at its expiry time.
+func ChannelExpiryQueueInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) error {
+		store := ctx.KVStore(k.storeKey)
+		numExpiring := 0
+		var err error
+		k.iterateChannels(ctx, func(channel types.Channel) bool {
+			if channel.Expiry.IsZero() {
+				return false
+			}
+			numExpiring++
+			if !store.Has(types.GetChannelExpiryQueueKey(channel.Expiry, channel.ID)) {
+				err = fmt.Errorf("channel %d is missing from the expiry queue", channel.ID)
+				return true
+			}
+			return false
+		})
+		if err != nil {
+			return err
+		}
+
+		numQueued := 0
+		iter := sdk.KVStorePrefixIterator(store, types.ChannelExpiryQueueKeyPrefix)
+		for ; iter.Valid(); iter.Next() {
+			numQueued++
+		}
+		iter.Close()
+		if numQueued != numExpiring {
+			return fmt.Errorf("channel expiry queue has %d entries but %d channels have an expiry", numQueued, numExpiring)
+		}
+		return nil
+	}
+}
+
+// LockedCoinsInvariant checks the coins held by the module account cover the total locked in channels, including penalty bonds,
+// plus the pending hash locks of closed channels.
+// The module account can hold more, as anyone can send coins to its address.
+func LockedCoinsInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) error {
+		locked := sdk.Coins{}
+		k.iterateChannels(ctx, func(channel types.Channel) bool {
+			locked = locked.Add(channel.Coins).Add(channel.PenaltyBond)
+			return false
+		})
+		k.iteratePendingHashLocks(ctx, func(lock types.PendingHashLock) bool {
+			locked = locked.Add(lock.Amount)
+			return false
+		})
+		held := k.getEscrowedCoins(ctx)
+		if !held.IsAllGTE(locked) {
+			return fmt.Errorf("module account holds %s but channels have %s locked", held, locked)
+		}
+		return nil
+	}
+}
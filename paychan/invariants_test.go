@@ -0,0 +1,141 @@
+package paychan
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+func TestInvariants(t *testing.T) {
+	// setupChannels creates two channels, starting a close on the first one.
+	setupChannels := func() (sdk.Context, Keeper) {
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, _, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+		channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], usd(10), 0, nil, usd(2), time.Time{})
+		channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], usd(5), 0, nil, nil, time.Time{})
+		channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(4, 6), 0, privKeys[0]))
+		return ctx, channelKeeper
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+
+		// CHECK RESULTS
+		assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+
+		// ACTION close the channel
+		EndBlocker(ctx.WithBlockTime(ctx.BlockHeader().Time.Add(types.DefaultParams().DefaultDisputePeriod)), channelKeeper)
+
+		// CHECK RESULTS
+		_, found := channelKeeper.getChannel(ctx, 0)
+		assert.False(t, found)
+		assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+	})
+	t.Run("OrphanSubmittedUpdate", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+
+		// ACTION
+		channelKeeper.addToSubmittedUpdatesQueue(ctx, types.SubmittedUpdate{
+			Update:        types.Update{ChannelID: 7, Payout: types.Payout{usd(1), usd(1)}},
+			ExecutionTime: time.Unix(0, 0),
+		})
+
+		// CHECK RESULTS
+		assert.NotNil(t, SubmittedUpdatesInvariant(channelKeeper)(ctx))
+		assert.Nil(t, SubmittedUpdatesQueueInvariant(channelKeeper)(ctx))
+	})
+	t.Run("SubmittedUpdateNotQueued", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+
+		// ACTION
+		sUpdate, _ := channelKeeper.getSubmittedUpdate(ctx, 0)
+		ctx.KVStore(channelKeeper.storeKey).Delete(types.GetSubmittedUpdatesQueueKey(sUpdate.ExecutionTime, 0))
+
+		// CHECK RESULTS
+		assert.NotNil(t, SubmittedUpdatesQueueInvariant(channelKeeper)(ctx))
+	})
+	t.Run("QueuedWithoutSubmittedUpdate", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+
+		// ACTION
+		channelKeeper.deleteSubmittedUpdate(ctx, 0)
+
+		// CHECK RESULTS
+		assert.NotNil(t, SubmittedUpdatesQueueInvariant(channelKeeper)(ctx))
+	})
+	t.Run("UnissuedChannelID", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+		channel, _ := channelKeeper.getChannel(ctx, 1)
+
+		// ACTION
+		channel.ID = 2
+		channelKeeper.setChannel(ctx, channel)
+
+		// CHECK RESULTS
+		assert.NotNil(t, ChannelIDsInvariant(channelKeeper)(ctx))
+	})
+	t.Run("MissingChannelIndexEntry", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+		channel, _ := channelKeeper.getChannel(ctx, 1)
+
+		// ACTION
+		ctx.KVStore(channelKeeper.storeKey).Delete(types.GetChannelsByReceiverKey(channel.Participants[1], channel.ID))
+
+		// CHECK RESULTS
+		assert.NotNil(t, ChannelIndexesInvariant(channelKeeper)(ctx))
+	})
+	t.Run("StaleChannelIndexEntry", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+		channel, _ := channelKeeper.getChannel(ctx, 1)
+
+		// ACTION
+		ctx.KVStore(channelKeeper.storeKey).Delete(types.GetChannelKey(channel.ID))
+
+		// CHECK RESULTS
+		assert.NotNil(t, ChannelIndexesInvariant(channelKeeper)(ctx))
+	})
+	t.Run("MissingChannelExpiryQueueEntry", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+		channel, _ := channelKeeper.getChannel(ctx, 1)
+
+		// ACTION give the channel an expiry without queueing it
+		channel.Expiry = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+		ctx.KVStore(channelKeeper.storeKey).Set(types.GetChannelKey(channel.ID), channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(channel))
+
+		// CHECK RESULTS
+		assert.NotNil(t, ChannelExpiryQueueInvariant(channelKeeper)(ctx))
+	})
+	t.Run("LockedCoinsNotCovered", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+
+		// ACTION pay out more than the channels have locked, as a bug in closeChannel could
+		channelKeeper.bankKeeper.SubtractCoins(ctx, types.ModuleAddress, usd(1))
+
+		// CHECK RESULTS
+		assert.NotNil(t, LockedCoinsInvariant(channelKeeper)(ctx))
+		assert.NotNil(t, AllInvariants(channelKeeper)(ctx))
+	})
+	t.Run("ExtraEscrowedCoins", func(t *testing.T) {
+		// SETUP
+		ctx, channelKeeper := setupChannels()
+
+		// ACTION someone sends coins straight to the module account
+		channelKeeper.bankKeeper.AddCoins(ctx, types.ModuleAddress, sdk.Coins{sdk.NewInt64Coin("eur", 1)})
+
+		// CHECK RESULTS
+		assert.Nil(t, LockedCoinsInvariant(channelKeeper)(ctx))
+	})
+}
@@ -2,10 +2,17 @@ package paychan
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/multisig"
 
 	"github.com/kava-labs/cosmos-paychan/paychan/types"
 )
@@ -17,20 +24,39 @@ type Keeper struct {
 	storeKey   sdk.StoreKey
 	cdc        *codec.Codec
 	bankKeeper bank.Keeper
+	paramSpace params.Subspace
+	codespace  sdk.CodespaceType
 }
 
 // NewKeeper returns a new payment channel keeper. This is called when creating new app.
-func NewKeeper(cdc *codec.Codec, key sdk.StoreKey, bk bank.Keeper) Keeper {
+func NewKeeper(cdc *codec.Codec, key sdk.StoreKey, bk bank.Keeper, paramSpace params.Subspace, codespace sdk.CodespaceType) Keeper {
 	keeper := Keeper{
 		storeKey:   key,
 		cdc:        cdc,
 		bankKeeper: bk,
+		paramSpace: paramSpace.WithKeyTable(types.ParamKeyTable()),
+		codespace:  codespace,
 	}
 	return keeper
 }
 
+// GetParams returns the module parameters.
+func (k Keeper) GetParams(ctx sdk.Context) types.Params {
+	var params types.Params
+	k.paramSpace.GetParamSet(ctx, &params)
+	return params
+}
+
+// SetParams sets the module parameters.
+func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
+	k.paramSpace.SetParamSet(ctx, &params)
+}
+
 // CreateChannel creates a new payment channel in the blockchain and locks up sender funds.
-func (k Keeper) CreateChannel(ctx sdk.Context, sender sdk.AccAddress, receiver sdk.AccAddress, coins sdk.Coins) (sdk.Tags, sdk.Error) {
+// A disputePeriod of zero means the channel uses the module's default dispute period.
+// If signingPubKey is not nil, updates must be signed with it rather than the sender's account key.
+// The penaltyBond is optional, it is locked up from the sender in addition to the channel coins.
+func (k Keeper) CreateChannel(ctx sdk.Context, sender sdk.AccAddress, receiver sdk.AccAddress, coins sdk.Coins, disputePeriod time.Duration, signingPubKey crypto.PubKey, penaltyBond sdk.Coins, expiry time.Time) (sdk.Tags, sdk.Error) {
 
 	// Check addresses valid (Technically don't need to check sender address is valid as SubtractCoins checks)
 	if sender.Empty() {
@@ -46,70 +72,248 @@ func (k Keeper) CreateChannel(ctx sdk.Context, sender sdk.AccAddress, receiver s
 	if !coins.IsAllPositive() {
 		return nil, sdk.ErrInvalidCoins(coins.String())
 	}
+	// check penalty bond is sorted and positive, it can be empty
+	if !penaltyBond.IsValid() {
+		return nil, sdk.ErrInvalidCoins(penaltyBond.String())
+	}
+	// check dispute period is within the allowed bounds
+	disputePeriod, err := k.getValidDisputePeriod(ctx, disputePeriod)
+	if err != nil {
+		return nil, err
+	}
+	// check expiry, if set, is in the future
+	if !expiry.IsZero() && !expiry.After(ctx.BlockHeader().Time) {
+		return nil, types.ErrInvalidExpiry(k.codespace, fmt.Sprintf("expiry %s must be after the current block time", expiry))
+	}
+
+	// move coins and bond from sender into the module account
+	err = k.bankKeeper.SendCoins(ctx, sender, types.ModuleAddress, coins.Add(penaltyBond))
+	if err != nil {
+		return nil, err
+	}
+	// Calculate next id
+	id := k.getNewChannelID(ctx)
+	// create new Paychan struct
+	channel := types.Channel{
+		ID:            id,
+		Participants:  []sdk.AccAddress{sender, receiver},
+		Coins:         coins,
+		DisputePeriod: disputePeriod,
+		SigningPubKey: signingPubKey,
+		PenaltyBond:   penaltyBond,
+		Expiry:        expiry,
+	}
+	// save to db
+	k.setChannel(ctx, channel)
+
+	return channelTags(channel), nil
+}
+
+// CreateMultipartyChannel creates a new payment channel between two or more participants, locking up funds from each of them.
+// Any participant can pay the others, and any can close the channel subject to a dispute period.
+// Updates must be signed by sigThreshold participants, zero means all of them.
+// A disputePeriod of zero means the channel uses the module's default dispute period.
+func (k Keeper) CreateMultipartyChannel(ctx sdk.Context, participants []sdk.AccAddress, deposits types.Payout, sigThreshold uint64, disputePeriod time.Duration) (sdk.Tags, sdk.Error) {
 
-	// subtract coins from sender
-	_, err := k.bankKeeper.SubtractCoins(ctx, sender, coins)
+	// Check addresses valid and distinct, so no one can count twice towards the signature threshold
+	if len(participants) < 2 {
+		return nil, types.ErrInvalidParticipants(k.codespace, "channel must have at least two participants")
+	}
+	for i, addr := range participants {
+		if addr.Empty() {
+			return nil, sdk.ErrInvalidAddress(addr.String())
+		}
+		for _, other := range participants[:i] {
+			if addr.Equals(other) {
+				return nil, sdk.ErrInvalidAddress(fmt.Sprintf("duplicate participant %s", addr))
+			}
+		}
+	}
+	// check there is a deposit for each participant
+	if len(deposits) != len(participants) {
+		return nil, types.ErrInvalidParticipants(k.codespace, "deposits don't match number of channel participants")
+	}
+	// check threshold, defaulting to all participants
+	if sigThreshold == 0 {
+		sigThreshold = uint64(len(participants))
+	}
+	if sigThreshold > uint64(len(participants)) {
+		return nil, types.ErrInvalidParticipants(k.codespace, fmt.Sprintf("signature threshold %d is more than the number of participants", sigThreshold))
+	}
+	// check coins are sorted and not negative, a participant can deposit nothing but the channel can't be empty
+	if !deposits.IsValid() || deposits.IsAnyNegative() {
+		return nil, sdk.ErrInvalidCoins(fmt.Sprintf("%v", deposits))
+	}
+	total := deposits.Sum()
+	if total.Empty() {
+		return nil, sdk.ErrInvalidCoins(total.String())
+	}
+	// check dispute period is within the allowed bounds
+	disputePeriod, err := k.getValidDisputePeriod(ctx, disputePeriod)
 	if err != nil {
 		return nil, err
 	}
+
+	// move coins from participants into the module account
+	for i, coins := range deposits {
+		err = k.bankKeeper.SendCoins(ctx, participants[i], types.ModuleAddress, coins)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// Calculate next id
 	id := k.getNewChannelID(ctx)
 	// create new Paychan struct
 	channel := types.Channel{
-		ID:           id,
-		Participants: [2]sdk.AccAddress{sender, receiver},
-		Coins:        coins,
+		ID:            id,
+		Participants:  participants,
+		Coins:         total,
+		DisputePeriod: disputePeriod,
+		Multiparty:    true,
+		SigThreshold:  sigThreshold,
 	}
 	// save to db
 	k.setChannel(ctx, channel)
 
-	// TODO add to tags
+	return channelTags(channel), nil
+}
 
-	return sdk.EmptyTags(), err
+// getValidDisputePeriod checks a channel's dispute period is within the bounds set in the params, replacing zero with the default.
+// It errors if the params themselves are invalid, as they may have been set by a param change proposal without validation.
+func (k Keeper) getValidDisputePeriod(ctx sdk.Context, disputePeriod time.Duration) (time.Duration, sdk.Error) {
+	params := k.GetParams(ctx)
+	if err := params.ValidateDisputePeriods(); err != nil {
+		return 0, types.ErrInvalidDisputePeriod(k.codespace, err.Error())
+	}
+	if disputePeriod == 0 {
+		disputePeriod = params.DefaultDisputePeriod
+	}
+	if disputePeriod < params.MinDisputePeriod || disputePeriod > params.MaxDisputePeriod {
+		return 0, types.ErrInvalidDisputePeriod(k.codespace, fmt.Sprintf("dispute period %s must be between %s and %s", disputePeriod, params.MinDisputePeriod, params.MaxDisputePeriod))
+	}
+	return disputePeriod, nil
+}
+
+// Deposit adds coins from the sender into an existing channel.
+// Deposits are not allowed while a close by the sender is pending, as the dispute period has already started.
+func (k Keeper) Deposit(ctx sdk.Context, channelID types.ChannelID, depositor sdk.AccAddress, coins sdk.Coins) (sdk.Tags, sdk.Error) {
+
+	// get the channel
+	channel, found := k.getChannel(ctx, channelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, channelID)
+	}
+	// multiparty channel balances can only change through updates signed by the participants
+	if channel.Multiparty {
+		return nil, types.ErrNotSupportedByMultiparty(k.codespace, "can't deposit into a multiparty channel")
+	}
+	// only the sender can top up a channel
+	if !depositor.Equals(channel.Participants[0]) {
+		return nil, types.ErrWrongSubmitter(k.codespace, "only the channel sender can deposit")
+	}
+	// check coins are sorted and positive
+	if !coins.IsValid() || !coins.IsAllPositive() {
+		return nil, sdk.ErrInvalidCoins(coins.String())
+	}
+	if k.hasSubmittedUpdate(ctx, channelID) {
+		return nil, types.ErrChannelClosing(k.codespace, "can't deposit into a channel that is closing")
+	}
+	if channel.IsExpired(ctx.BlockHeader().Time) {
+		return nil, types.ErrChannelExpired(k.codespace, "can't deposit into an expired channel")
+	}
+
+	// move coins from sender into the module account
+	err := k.bankKeeper.SendCoins(ctx, depositor, types.ModuleAddress, coins)
+	if err != nil {
+		return nil, err
+	}
+	channel.Coins = channel.Coins.Add(coins)
+	k.setChannel(ctx, channel)
+
+	return channelTags(channel), nil
+}
+
+// RotateSigningKey replaces the key the sender signs a unidirectional channel's updates with.
+// Updates signed with the previous key are rejected from then on, but one already submitted in a pending close still executes.
+func (k Keeper) RotateSigningKey(ctx sdk.Context, channelID types.ChannelID, sender sdk.AccAddress, newSigningPubKey crypto.PubKey) (sdk.Tags, sdk.Error) {
+
+	// get the channel
+	channel, found := k.getChannel(ctx, channelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, channelID)
+	}
+	if channel.Multiparty {
+		return nil, types.ErrNotSupportedByMultiparty(k.codespace, "can't set a signing key on a multiparty channel")
+	}
+	// only the sender can change their key
+	if !sender.Equals(channel.Participants[0]) {
+		return nil, types.ErrWrongSubmitter(k.codespace, "only the channel sender can rotate the signing key")
+	}
+	if newSigningPubKey == nil {
+		return nil, sdk.ErrInvalidPubKey("new signing key cannot be empty")
+	}
+
+	channel.SigningPubKey = newSigningPubKey
+	k.setChannel(ctx, channel)
+
+	return channelTags(channel), nil
 }
 
 // InitCloseChannelBySender initiates the close of a payment channel, subject to a dispute period.
+// In multiparty channels any participant can initiate a close, and during the dispute period
+// the pending update can be replaced by one with a higher sequence number.
 func (k Keeper) InitCloseChannelBySender(ctx sdk.Context, update types.Update) (sdk.Tags, sdk.Error) {
 	// This is roughly the default path for non unidirectional channels
 
 	// get the channel
 	channel, found := k.getChannel(ctx, update.ChannelID)
 	if !found {
-		return nil, sdk.ErrInternal("Channel doesn't exist")
+		return nil, types.ErrUnknownChannel(k.codespace, update.ChannelID)
+	}
+	if channel.IsExpired(ctx.BlockHeader().Time) {
+		return nil, types.ErrChannelExpired(k.codespace, "expired channels can be reclaimed by the sender without an update")
 	}
-	err := VerifyUpdate(channel, update)
+	err := VerifyUpdate(k.codespace, channel, update)
 	if err != nil {
 		return nil, err
 	}
 
-	q := k.getSubmittedUpdatesQueue(ctx)
-	if q.Contains(update.ChannelID) {
+	var sUpdate types.SubmittedUpdate
+	existingSUpdate, found := k.getSubmittedUpdate(ctx, update.ChannelID)
+	if found {
 		// Someone has previously tried to update channel
 
-		// In bidirectional channels the new update is compared against existing and replaces it if it has a higher sequence number.
+		// In unidirectional case, only the sender can close a channel this way. No clear need for them to be able to submit an update replacing a previous one they sent, so don't allow it.
+		if !channel.Multiparty {
+			return nil, types.ErrDuplicateClose(k.codespace)
+		}
 
-		// existingSUpdate, found := k.getSubmittedUpdate(ctx, update.ChannelID)
-		// if !found {
-		// 	panic("can't find element in queue that should exist")
-		// }
-		// k.addToSubmittedUpdatesQueue(ctx, k.applyNewUpdate(existingSUpdate, update))
+		// The dispute period is over once the execution time is reached, even if the close hasn't been executed yet in this block
+		if !ctx.BlockHeader().Time.Before(existingSUpdate.ExecutionTime) {
+			return nil, types.ErrChannelClosing(k.codespace, "dispute period has ended, the submitted update can no longer be replaced")
+		}
 
-		// However in unidirectional case, only the sender can close a channel this way. No clear need for them to be able to submit an update replacing a previous one they sent, so don't allow it.
-		// TODO tags
-		// TODO custom errors
-		return nil, sdk.ErrInternal("Sender can't submit an update for channel if one has already been submitted.")
+		// In multiparty channels the new update is compared against existing and replaces it if it has a higher sequence number.
+		var replaced bool
+		sUpdate, replaced = applyNewUpdate(existingSUpdate, update)
+		if !replaced {
+			return nil, types.ErrStaleUpdate(k.codespace, fmt.Sprintf("update sequence number must be higher than the already submitted %d", existingSUpdate.Sequence))
+		}
+		k.addToSubmittedUpdatesQueue(ctx, sUpdate)
 	} else {
 		// No one has tried to update channel
-		submittedUpdate := types.SubmittedUpdate{
+		sUpdate = types.SubmittedUpdate{
 			Update:        update,
-			ExecutionTime: ctx.BlockHeight() + types.ChannelDisputeTime,
+			ExecutionTime: ctx.BlockHeader().Time.Add(channel.DisputePeriod),
 		}
-		k.addToSubmittedUpdatesQueue(ctx, submittedUpdate)
+		k.addToSubmittedUpdatesQueue(ctx, sUpdate)
 	}
 
-	// TODO tags
-
-	return sdk.EmptyTags(), nil
+	// Watchers can use the execution time to know when they need to respond by
+	tags := channelTags(channel).
+		AppendTag(types.TagPayout, sUpdate.Payout.String()).
+		AppendTag(types.TagExecutionTime, string(sdk.FormatTimeBytes(sUpdate.ExecutionTime)))
+	return tags, nil
 }
 
 // CloseChannelByReceiver immediately closes a payment channel.
@@ -118,67 +322,334 @@ func (k Keeper) CloseChannelByReceiver(ctx sdk.Context, update types.Update) (sd
 	// get the channel
 	channel, found := k.getChannel(ctx, update.ChannelID)
 	if !found {
-		return nil, sdk.ErrInternal("Channel doesn't exist")
+		return nil, types.ErrUnknownChannel(k.codespace, update.ChannelID)
+	}
+	if channel.Multiparty {
+		return nil, types.ErrNotSupportedByMultiparty(k.codespace, "multiparty channels can't be closed immediately by one participant")
+	}
+	if channel.IsExpired(ctx.BlockHeader().Time) {
+		return nil, types.ErrChannelExpired(k.codespace, "receiver can't close a channel after it has expired")
 	}
-	err := VerifyUpdate(channel, update)
+	err := VerifyUpdate(k.codespace, channel, update)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if there is an update in the queue already
-	q := k.getSubmittedUpdatesQueue(ctx)
-	if q.Contains(update.ChannelID) {
+	existingSUpdate, found := k.getSubmittedUpdate(ctx, update.ChannelID)
+	if found {
 		// Someone has previously tried to update channel but receiver has final say
 		k.removeFromSubmittedUpdatesQueue(ctx, update.ChannelID)
+
+		// Penalise the sender if they tried to close paying the receiver less than they were owed
+		penalty := calculatePenalty(channel.PenaltyBond, k.GetParams(ctx).PenaltyShare, existingSUpdate.Update, update)
+		if !penalty.IsZero() {
+			err := k.bankKeeper.SendCoins(ctx, types.ModuleAddress, channel.Participants[len(channel.Participants)-1], penalty)
+			if err != nil {
+				panic(err)
+			}
+			channel.PenaltyBond = channel.PenaltyBond.Sub(penalty)
+			k.setChannel(ctx, channel)
+		}
+	}
+
+	tags, err := k.closeChannel(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTag(types.TagCloseType, types.CloseTypeReceiver), nil
+}
+
+// Withdraw pays the receiver the difference between their payout in the given update and what they have already withdrawn.
+// The channel stays open. Withdrawals are not allowed while a close by the sender is pending, the receiver should close instead.
+func (k Keeper) Withdraw(ctx sdk.Context, update types.Update) (sdk.Tags, sdk.Error) {
+
+	// get the channel
+	channel, found := k.getChannel(ctx, update.ChannelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, update.ChannelID)
+	}
+	if channel.Multiparty {
+		return nil, types.ErrNotSupportedByMultiparty(k.codespace, "can't withdraw from a multiparty channel")
+	}
+	err := VerifyUpdate(k.codespace, channel, update)
+	if err != nil {
+		return nil, err
+	}
+	if k.hasSubmittedUpdate(ctx, update.ChannelID) {
+		return nil, types.ErrChannelClosing(k.codespace, "can't withdraw from a channel that is closing")
+	}
+	if channel.IsExpired(ctx.BlockHeader().Time) {
+		return nil, types.ErrChannelExpired(k.codespace, "can't withdraw from a channel after it has expired")
+	}
+
+	// Calculate amount owed to receiver that hasn't been withdrawn
+	receiverIndex := len(channel.Participants) - 1
+	amount := update.Payout[receiverIndex].Sub(channel.Withdrawn)
+	if amount.IsZero() {
+		return nil, types.ErrNothingToWithdraw(k.codespace)
+	}
+
+	// Pay out to receiver
+	err = k.bankKeeper.SendCoins(ctx, types.ModuleAddress, channel.Participants[receiverIndex], amount)
+	if err != nil {
+		return nil, err
 	}
+	channel.Coins = channel.Coins.Sub(amount)
+	channel.Withdrawn = channel.Withdrawn.Add(amount)
+	k.setChannel(ctx, channel)
+
+	return channelTags(channel).AppendTag(types.TagPayout, update.Payout.String()), nil
+}
+
+// BatchCloseChannelsByReceiver immediately closes each channel in a batch of updates submitted by their receiver.
+// Each update is closed in its own cached context so one that fails leaves no changes behind and doesn't stop the rest.
+// It returns the tags of the closed channels, and the outcome for each update in order.
+func (k Keeper) BatchCloseChannelsByReceiver(ctx sdk.Context, updates []types.Update, receiver sdk.AccAddress) (sdk.Tags, []types.BatchCloseResult) {
+	tags := sdk.EmptyTags()
+	results := make([]types.BatchCloseResult, len(updates))
+	for i, update := range updates {
+		closeTags, err := k.batchCloseChannelByReceiver(ctx, update, receiver)
+		results[i] = types.BatchCloseResult{ChannelID: update.ChannelID}
+		if err != nil {
+			results[i].Codespace = err.Codespace()
+			results[i].Code = err.Code()
+			results[i].Log = err.Error()
+			continue
+		}
+		tags = tags.AppendTags(closeTags)
+	}
+	return tags, results
+}
+
+// batchCloseChannelByReceiver closes a channel for BatchCloseChannelsByReceiver, only writing the changes to the store if the close succeeds.
+func (k Keeper) batchCloseChannelByReceiver(ctx sdk.Context, update types.Update, receiver sdk.AccAddress) (sdk.Tags, sdk.Error) {
+	channel, found := k.getChannel(ctx, update.ChannelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, update.ChannelID)
+	}
+	participants := channel.Participants
+	if channel.Multiparty || !receiver.Equals(participants[len(participants)-1]) {
+		return nil, types.ErrWrongSubmitter(k.codespace, "update submitter does not match channel receiver")
+	}
+	cacheCtx, write := ctx.CacheContext()
+	tags, err := k.CloseChannelByReceiver(cacheCtx, update)
+	if err != nil {
+		return nil, err
+	}
+	write()
+	return tags, nil
+}
+
+// RelayCloseChannelByReceiver closes a channel immediately on behalf of the receiver, using an authorization they signed for the update.
+// It lets anyone, such as a watchtower, respond to a close by the sender while the receiver is offline. The authorization's fee is paid
+// to the relayer out of the receiver's payout.
+func (k Keeper) RelayCloseChannelByReceiver(ctx sdk.Context, update types.Update, authorization types.CloseAuthorization, relayer sdk.AccAddress) (sdk.Tags, sdk.Error) {
+
+	// get the channel
+	channel, found := k.getChannel(ctx, update.ChannelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, update.ChannelID)
+	}
+	if channel.Multiparty {
+		return nil, types.ErrNotSupportedByMultiparty(k.codespace, "multiparty channels can't be closed immediately by one participant")
+	}
+	if relayer.Empty() {
+		return nil, sdk.ErrInvalidAddress(relayer.String())
+	}
+	// check fee is sorted and positive, it can be empty
+	if !authorization.Fee.IsValid() {
+		return nil, sdk.ErrInvalidCoins(authorization.Fee.String())
+	}
+	err := VerifyUpdate(k.codespace, channel, update)
+	if err != nil {
+		return nil, err
+	}
+	receiver := channel.Participants[len(channel.Participants)-1]
+	if !hasValidSignature([]types.UpdateSignature{authorization.Sig}, receiver, authorization.GetSignBytes(update)) {
+		return nil, types.ErrInvalidSignature(k.codespace, "close authorization not signed by channel receiver")
+	}
+	// The fee comes out of what the receiver is paid on close, ie their payout minus what they have already withdrawn
+	if !update.Payout[len(update.Payout)-1].IsAllGTE(channel.Withdrawn.Add(authorization.Fee)) {
+		return nil, types.ErrPayoutMismatch(k.codespace, "relayer fee exceeds what the receiver is paid on close")
+	}
+
+	tags, err := k.CloseChannelByReceiver(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+	err = k.bankKeeper.SendCoins(ctx, receiver, relayer, authorization.Fee)
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTag(types.TagRelayer, relayer.String()), nil
+}
+
+// CooperativeCloseChannel closes a channel immediately with an update signed by all participants, overriding any pending close.
+func (k Keeper) CooperativeCloseChannel(ctx sdk.Context, update types.Update) (sdk.Tags, sdk.Error) {
+
+	// get the channel
+	channel, found := k.getChannel(ctx, update.ChannelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, update.ChannelID)
+	}
+	err := VerifyUpdate(k.codespace, channel, update)
+	if err != nil {
+		return nil, err
+	}
+	if !verifySignatures(signingAddresses(channel), len(channel.Participants), update) {
+		return nil, types.ErrInvalidSignature(k.codespace, "update not signed by all participants")
+	}
+
+	// Participants have agreed the final balance so any pending close is superseded
+	k.removeFromSubmittedUpdatesQueue(ctx, update.ChannelID)
 
 	tags, err := k.closeChannel(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+	return tags.AppendTag(types.TagCloseType, types.CloseTypeCooperative), nil
+}
+
+// ReclaimExpiredChannel closes an expired channel, returning the coins remaining in it and the penalty bond to the sender.
+// No update is needed, the receiver had until the expiry to close with their best update. Any pending close by the sender is superseded.
+func (k Keeper) ReclaimExpiredChannel(ctx sdk.Context, channelID types.ChannelID, sender sdk.AccAddress) (sdk.Tags, sdk.Error) {
+
+	// get the channel
+	channel, found := k.getChannel(ctx, channelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, channelID)
+	}
+	if channel.Multiparty {
+		return nil, types.ErrNotSupportedByMultiparty(k.codespace, "multiparty channels don't expire")
+	}
+	// only the sender can reclaim a channel
+	if !sender.Equals(channel.Participants[0]) {
+		return nil, types.ErrWrongSubmitter(k.codespace, "only the channel sender can reclaim an expired channel")
+	}
+	if !channel.IsExpired(ctx.BlockHeader().Time) {
+		return nil, types.ErrChannelNotExpired(k.codespace, channelID)
+	}
 
-	return tags, err
+	return k.reclaimChannel(ctx, channel), nil
+}
+
+// RevealPreimage records the block height a hash lock preimage was revealed at.
+// This unlocks payments with its hash in any channel, both in updates submitted later and in locks pending from closed channels.
+func (k Keeper) RevealPreimage(ctx sdk.Context, preimage []byte) (sdk.Tags, sdk.Error) {
+	if len(preimage) == 0 || len(preimage) > types.MaxPreimageLength {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("preimage must be between 1 and %d bytes", types.MaxPreimageLength))
+	}
+	hash := sha256.Sum256(preimage)
+	if _, found := k.getPreimageRevealHeight(ctx, hash[:]); found {
+		return nil, types.ErrPreimageAlreadyRevealed(k.codespace, hash[:])
+	}
+	k.setPreimageRevealHeight(ctx, hash[:], ctx.BlockHeight())
+
+	return sdk.NewTags(
+		types.TagCategory, types.TxCategory,
+		types.TagHash, hex.EncodeToString(hash[:]),
+	), nil
+}
+
+// calculatePenalty returns the share of a sender's penalty bond forfeited to the receiver when the receiver overrides the sender's
+// submitted update with one that pays the receiver more. Amounts are rounded down.
+// Hash locked amounts count towards what each update pays the receiver, so an override that only moves a resolved hash lock into
+// the receiver's payout isn't penalised.
+// The share is clamped between 0 and 1 as param change proposals aren't validated, so the penalty never exceeds the bond.
+// Pure function.
+func calculatePenalty(penaltyBond sdk.Coins, penaltyShare sdk.Dec, senderUpdate types.Update, receiverUpdate types.Update) sdk.Coins {
+	if penaltyShare.IsNil() || !penaltyShare.IsPositive() {
+		return sdk.Coins{}
+	}
+	penaltyShare = sdk.MinDec(penaltyShare, sdk.OneDec())
+	receiverIndex := len(receiverUpdate.Payout) - 1
+	senderOffer := senderUpdate.Payout[receiverIndex].Add(senderUpdate.HashLockedCoins())
+	receiverClaim := receiverUpdate.Payout[receiverIndex].Add(receiverUpdate.HashLockedCoins())
+	if senderOffer.IsAllGTE(receiverClaim) {
+		// sender's update didn't pay the receiver less, no penalty
+		return sdk.Coins{}
+	}
+	penalty := sdk.Coins{}
+	for _, coin := range penaltyBond {
+		amount := penaltyShare.MulInt(coin.Amount).TruncateInt()
+		if amount.IsPositive() {
+			penalty = penalty.Add(sdk.Coins{sdk.NewCoin(coin.Denom, amount)})
+		}
+	}
+	return penalty
 }
 
 // Main function that compares updates against each other.
 // Pure function, Not needed in unidirectional case.
-// func (k Keeper) applyNewUpdate(existingSUpdate SubmittedUpdate, proposedUpdate Update) SubmittedUpdate {
-// 	var returnUpdate SubmittedUpdate
-
-// 	if existingSUpdate.Sequence > proposedUpdate.Sequence {
-// 		// update accepted
-// 		returnUpdate = SubmittedUpdate{
-// 			Update:        proposedUpdate,
-// 			ExecutionTime: existingSUpdate.ExecutionTime, // FIXME any new update proposal should be subject to full dispute period from submission
-// 		}
-// 	} else {
-// 		// update rejected
-// 		returnUpdate = existingSUpdate
-// 	}
-// 	return returnUpdate
-// }
+// A proposed update replaces the existing one only if it has a higher sequence number. The replacement keeps the original
+// execution time so a dispute can't be extended indefinitely, the counterparty has already had the chance to respond.
+func applyNewUpdate(existingSUpdate types.SubmittedUpdate, proposedUpdate types.Update) (types.SubmittedUpdate, bool) {
+	if proposedUpdate.Sequence <= existingSUpdate.Sequence {
+		// update rejected
+		return existingSUpdate, false
+	}
+	// update accepted
+	return types.SubmittedUpdate{
+		Update:        proposedUpdate,
+		ExecutionTime: existingSUpdate.ExecutionTime,
+	}, true
+}
 
 // VerifyUpdate checks that a given update is valid for a given channel.
-func VerifyUpdate(channel types.Channel, update types.Update) sdk.Error {
+// Errors are returned in the given codespace.
+func VerifyUpdate(codespace sdk.CodespaceType, channel types.Channel, update types.Update) sdk.Error {
 
 	// Check the num of payout participants match channel participants
 	if len(update.Payout) != len(channel.Participants) {
-		return sdk.ErrInternal("Payout doesn't match number of channel participants")
+		return types.ErrInvalidPayout(codespace, "payout doesn't match number of channel participants")
 	}
 	// Check each coins are valid
 	for _, coins := range update.Payout {
 		if !coins.IsValid() {
-			return sdk.ErrInternal("Payout coins aren't formatted correctly")
+			return types.ErrInvalidPayout(codespace, "payout coins aren't formatted correctly")
 		}
 	}
 	// Check payout coins are each not negative (can be zero though)
 	if update.Payout.IsAnyNegative() {
-		return sdk.ErrInternal("Payout cannot be negative")
+		return types.ErrInvalidPayout(codespace, "payout cannot be negative")
+	}
+	// Check payout sums to no more than the total ever put into the channel.
+	// Payouts can be less than the channel total as it may have been topped up since the update was signed.
+	total := update.Payout.Sum()
+	if total.Empty() && len(update.HashLocks) == 0 {
+		return types.ErrInvalidPayout(codespace, "payout cannot be empty")
+	}
+	// Multiparty channels can't be topped up so must be paid out exactly
+	// (compare both ways as IsEqual panics on mismatched denoms)
+	if channel.Multiparty && !(channel.Coins.IsAllGTE(total) && total.IsAllGTE(channel.Coins)) {
+		return types.ErrPayoutMismatch(codespace, "payout amount doesn't match channel amount")
+	}
+	if !channel.Coins.Add(channel.Withdrawn).IsAllGTE(total) {
+		return types.ErrPayoutMismatch(codespace, "payout amount exceeds channel amount")
 	}
-	// Check payout sums to match channel.Coins
-	if !channel.Coins.IsEqual(update.Payout.Sum()) {
-		return sdk.ErrInternal("Payout amount doesn't match channel amount")
+	// Check the receiver isn't paid less than they have already withdrawn, ie the update isn't older than a withdrawal
+	if !update.Payout[len(update.Payout)-1].IsAllGTE(channel.Withdrawn) {
+		return types.ErrPayoutMismatch(codespace, "payout to receiver is less than already withdrawn")
 	}
-	// Check sender signature is OK
-	if !verifySignatures(channel, update) {
-		return sdk.ErrInternal("Signature on update not valid")
+	// Check hash locks are well formed, they are paid by the sender on top of the payout so both must fit in the channel
+	if len(update.HashLocks) > 0 {
+		if channel.Multiparty {
+			return types.ErrNotSupportedByMultiparty(codespace, "hash locks are only supported in unidirectional channels")
+		}
+		for _, lock := range update.HashLocks {
+			if !lock.IsValid() {
+				return types.ErrInvalidHashLock(codespace, "hash lock must have a sha256 hash, a positive amount and an expiry height")
+			}
+		}
+		if !channel.Coins.Add(channel.Withdrawn).IsAllGTE(total.Add(update.HashLockedCoins())) {
+			return types.ErrInvalidHashLock(codespace, "payout and hash locked amounts exceed channel amount")
+		}
+	}
+	// Check sender (or in multiparty channels a threshold of participants) signatures are OK
+	signers, threshold := requiredSigners(channel)
+	if !verifySignatures(signers, threshold, update) {
+		return types.ErrInvalidSignature(codespace, "signature on update not valid")
 	}
 	return nil
 }
@@ -191,10 +662,43 @@ func (k Keeper) closeChannel(ctx sdk.Context, update types.Update) (sdk.Tags, sd
 	channel, _ := k.getChannel(ctx, update.ChannelID)
 	// TODO check channel exists and participants matches update payout length
 
-	// Add coins to sender and receiver
+	// Pay out coins from the module account to sender and receiver, minus anything the receiver has already withdrawn
 	// TODO check for possible errors first to avoid coins being half paid out?
+	paid := sdk.Coins{}
 	for i, coins := range update.Payout {
-		_, err = k.bankKeeper.AddCoins(ctx, channel.Participants[i], coins)
+		if i == len(update.Payout)-1 {
+			coins = coins.Sub(channel.Withdrawn)
+		}
+		err = k.bankKeeper.SendCoins(ctx, types.ModuleAddress, channel.Participants[i], coins)
+		if err != nil {
+			panic(err)
+		}
+		paid = paid.Add(coins)
+	}
+	// Pay hash locks unlocked in time to the receiver. Locks that haven't expired yet are kept in the module account until they do,
+	// expired locks are refunded to the sender with the remainder.
+	for i, lock := range update.HashLocks {
+		if k.isHashLockUnlocked(ctx, lock) {
+			err = k.bankKeeper.SendCoins(ctx, types.ModuleAddress, channel.Participants[len(channel.Participants)-1], lock.Amount)
+			if err != nil {
+				panic(err)
+			}
+			paid = paid.Add(lock.Amount)
+		} else if ctx.BlockHeight() < lock.Expiry {
+			k.setPendingHashLock(ctx, types.PendingHashLock{
+				HashLock:  lock,
+				ChannelID: channel.ID,
+				Index:     uint64(i),
+				Sender:    channel.Participants[0],
+				Receiver:  channel.Participants[len(channel.Participants)-1],
+			})
+			paid = paid.Add(lock.Amount)
+		}
+	}
+	// Refund any coins not covered by the payout (ie deposited after the update was signed) to the sender, along with their penalty bond
+	remainder := channel.Coins.Sub(paid).Add(channel.PenaltyBond)
+	if !remainder.Empty() {
+		err = k.bankKeeper.SendCoins(ctx, types.ModuleAddress, channel.Participants[0], remainder)
 		if err != nil {
 			panic(err)
 		}
@@ -202,80 +706,183 @@ func (k Keeper) closeChannel(ctx sdk.Context, update types.Update) (sdk.Tags, sd
 
 	k.deleteChannel(ctx, update.ChannelID)
 
-	// TODO tags
-	return sdk.EmptyTags(), nil
+	return channelTags(channel).AppendTag(types.TagPayout, update.Payout.String()), nil
 }
 
-// verifySignatures checks whether the signatures on a given update are correct.
-func verifySignatures(channel types.Channel, update types.Update) bool {
-	// In non unidirectional channels there will be more than one signature to check
+// reclaimChannel closes an expired channel, paying the coins remaining in it and the penalty bond to the sender.
+// If the sender submitted a close before the expiry it is settled now with its update, as the sender already agreed to pay the receiver that much.
+// The penalty bond is returned to the sender either way. It is only forfeited when the receiver overrides a close, which they can no longer do.
+func (k Keeper) reclaimChannel(ctx sdk.Context, channel types.Channel) sdk.Tags {
+	if sUpdate, found := k.getSubmittedUpdate(ctx, channel.ID); found {
+		k.removeFromSubmittedUpdatesQueue(ctx, channel.ID)
+		tags, err := k.closeChannel(ctx, sUpdate.Update)
+		if err != nil {
+			panic(err)
+		}
+		return tags.AppendTag(types.TagCloseType, types.CloseTypeExpired)
+	}
 
+	err := k.bankKeeper.SendCoins(ctx, types.ModuleAddress, channel.Participants[0], channel.Coins.Add(channel.PenaltyBond))
+	if err != nil {
+		panic(err)
+	}
+	k.deleteChannel(ctx, channel.ID)
+
+	// the receiver has already been paid what they withdrew
+	payout := types.Payout{channel.Coins, channel.Withdrawn}
+	return channelTags(channel).
+		AppendTag(types.TagPayout, payout.String()).
+		AppendTag(types.TagCloseType, types.CloseTypeExpired)
+}
+
+// channelTags returns the tags identifying a channel and its participants, common to all channel events.
+func channelTags(channel types.Channel) sdk.Tags {
+	tags := sdk.NewTags(
+		types.TagCategory, types.TxCategory,
+		types.TagChannelID, channel.ID.String(),
+	)
+	if channel.Multiparty {
+		for _, participant := range channel.Participants {
+			tags = tags.AppendTag(types.TagParticipant, participant.String())
+		}
+		return tags
+	}
+	return tags.
+		AppendTag(types.TagSender, channel.Participants[0].String()).
+		AppendTag(types.TagReceiver, channel.Participants[len(channel.Participants)-1].String())
+}
+
+// requiredSigners returns the addresses of the keys that can sign an update, and how many of them must for it to be valid.
+// Unidirectional channels only need the sender's signature, multiparty channels need the channel's threshold of participants.
+func requiredSigners(channel types.Channel) ([]sdk.AccAddress, int) {
+	signers := signingAddresses(channel)
+	if channel.Multiparty {
+		threshold := int(channel.SigThreshold)
+		if threshold == 0 {
+			threshold = len(signers)
+		}
+		return signers, threshold
+	}
+	return signers[:1], 1 // sender
+}
+
+// signingAddresses returns the addresses of the keys that sign updates for each participant.
+// These are the participants' account addresses, except where the sender has a dedicated channel signing key.
+func signingAddresses(channel types.Channel) []sdk.AccAddress {
+	signers := make([]sdk.AccAddress, len(channel.Participants))
+	copy(signers, channel.Participants)
+	if channel.SigningPubKey != nil {
+		signers[0] = sdk.AccAddress(channel.SigningPubKey.Address())
+	}
+	return signers
+}
+
+// verifySignatures checks whether the update has correct signatures from at least threshold of the given signers.
+// Signatures can be in any order.
+func verifySignatures(signers []sdk.AccAddress, threshold int, update types.Update) bool {
 	signBytes := update.GetSignBytes()
 
-	address := channel.Participants[0] // sender
-	pubKey := update.Sigs[0].PubKey
-	cryptoSig := update.Sigs[0].CryptoSignature
+	count := 0
+	for _, address := range signers {
+		if hasValidSignature(update.Sigs, address, signBytes) {
+			count++
+		}
+	}
+	return count >= threshold
+}
 
-	// Check public key submitted with update signature matches the account address
-	valid := bytes.Equal(pubKey.Address(), address) &&
-		// Check the signature is correct
-		pubKey.VerifyBytes(signBytes, cryptoSig)
-	return valid
+// hasValidSignature checks whether any of the signatures are by the key with the given address over the given bytes.
+// The key can be a multisig threshold key, with an amino encoded multisignature as the signature.
+func hasValidSignature(sigs []types.UpdateSignature, address sdk.AccAddress, signBytes []byte) bool {
+	for _, sig := range sigs {
+		if sig.PubKey == nil {
+			continue
+		}
+		// Check public key submitted with update signature matches the signer address
+		if bytes.Equal(sig.PubKey.Address(), address) &&
+			// Check the signature is correct
+			verifyBytes(sig.PubKey, signBytes, sig.CryptoSignature) {
+			return true
+		}
+	}
+	return false
+}
 
+// verifyBytes checks a signature by the given key over the given bytes.
+// Multisignatures are checked here rather than by the multisig key, which panics if a multisignature has fewer signatures than set bits.
+func verifyBytes(pubKey crypto.PubKey, signBytes []byte, sig []byte) bool {
+	multisigPubKey, ok := pubKey.(multisig.PubKeyMultisigThreshold)
+	if !ok {
+		return pubKey.VerifyBytes(signBytes, sig)
+	}
+	var multisignature multisig.Multisignature
+	if err := types.ModuleCdc.UnmarshalBinaryBare(sig, &multisignature); err != nil {
+		return false
+	}
+	size := multisignature.BitArray.Size()
+	if size != len(multisigPubKey.PubKeys) ||
+		multisignature.BitArray.NumTrueBitsBefore(size) != len(multisignature.Sigs) ||
+		len(multisignature.Sigs) < int(multisigPubKey.K) {
+		return false
+	}
+	// signatures are in the same order as the keys they are from
+	sigIndex := 0
+	for i, key := range multisigPubKey.PubKeys {
+		if multisignature.BitArray.GetIndex(i) {
+			if !verifyBytes(key, signBytes, multisignature.Sigs[sigIndex]) {
+				return false
+			}
+			sigIndex++
+		}
+	}
+	return true
 }
 
 // ============================================================
 // SUBMITTED UPDATES QUEUE
 // ============================================================
 
+// addToSubmittedUpdatesQueue stores a submitted update and queues it for execution, replacing any existing update for the channel.
 func (k Keeper) addToSubmittedUpdatesQueue(ctx sdk.Context, sUpdate types.SubmittedUpdate) {
 	// always overwrite prexisting values - leave paychan logic to higher levels
-	// get current queue
-	q := k.getSubmittedUpdatesQueue(ctx)
-	// append ID to queue
-	if !q.Contains(sUpdate.ChannelID) {
-		q = append(q, sUpdate.ChannelID)
-	}
-	// set queue
-	k.setSubmittedUpdatesQueue(ctx, q)
-	// store submittedUpdate
+	store := ctx.KVStore(k.storeKey)
+	if existingSUpdate, found := k.getSubmittedUpdate(ctx, sUpdate.ChannelID); found {
+		store.Delete(types.GetSubmittedUpdatesQueueKey(existingSUpdate.ExecutionTime, existingSUpdate.ChannelID))
+	}
+	store.Set(types.GetSubmittedUpdatesQueueKey(sUpdate.ExecutionTime, sUpdate.ChannelID), k.cdc.MustMarshalBinaryLengthPrefixed(sUpdate.ChannelID))
 	k.setSubmittedUpdate(ctx, sUpdate)
 }
+
+// removeFromSubmittedUpdatesQueue deletes a channel's submitted update and its queue entry, if there is one.
 func (k Keeper) removeFromSubmittedUpdatesQueue(ctx sdk.Context, channelID types.ChannelID) {
-	// get current queue
-	q := k.getSubmittedUpdatesQueue(ctx)
-	// remove id
-	q.RemoveMatchingElements(channelID)
-	// set queue
-	k.setSubmittedUpdatesQueue(ctx, q)
-	// delete submittedUpdate
+	sUpdate, found := k.getSubmittedUpdate(ctx, channelID)
+	if !found {
+		return
+	}
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetSubmittedUpdatesQueueKey(sUpdate.ExecutionTime, channelID))
 	k.deleteSubmittedUpdate(ctx, channelID)
 }
-func (k Keeper) getSubmittedUpdatesQueue(ctx sdk.Context) types.SubmittedUpdatesQueue {
-	// load from DB
-	store := ctx.KVStore(k.storeKey)
-	bz := store.Get(k.getSubmittedUpdatesQueueKey())
-
-	var suq types.SubmittedUpdatesQueue // if the submittedUpdatesQueue not found then return an empty one
-	if bz != nil {
-		// unmarshal
-		k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &suq)
-	}
-	return suq
 
-}
-func (k Keeper) setSubmittedUpdatesQueue(ctx sdk.Context, suq types.SubmittedUpdatesQueue) {
+// submittedUpdatesQueueIterator returns an iterator over the queue entries of updates with execution times up to and including endTime, earliest first.
+// Values are channel IDs.
+func (k Keeper) submittedUpdatesQueueIterator(ctx sdk.Context, endTime time.Time) sdk.Iterator {
 	store := ctx.KVStore(k.storeKey)
-	// marshal
-	bz := k.cdc.MustMarshalBinaryLengthPrefixed(suq)
-	// write to db
-	key := k.getSubmittedUpdatesQueueKey()
-	store.Set(key, bz)
+	return store.Iterator(types.SubmittedUpdatesQueueKeyPrefix, sdk.PrefixEndBytes(types.GetSubmittedUpdatesQueueTimeKey(endTime)))
 }
 
-// TODO should this move into keys or stay here? external parties shouldn't need to query the queue
-func (k Keeper) getSubmittedUpdatesQueueKey() []byte {
-	return []byte("submittedUpdatesQueue")
+// iterateSubmittedUpdatesQueue calls the provided function on the channel ID of every queue entry, in execution order, stopping early if it returns true.
+func (k Keeper) iterateSubmittedUpdatesQueue(ctx sdk.Context, cb func(channelID types.ChannelID) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.SubmittedUpdatesQueueKeyPrefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var channelID types.ChannelID
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &channelID)
+		if cb(channelID) {
+			break
+		}
+	}
 }
 
 // ============================================================
@@ -300,6 +907,12 @@ func (k Keeper) getSubmittedUpdate(ctx sdk.Context, channelID types.ChannelID) (
 	return sUpdate, true
 }
 
+// hasSubmittedUpdate returns whether a channel has a submitted update, ie it is closing.
+func (k Keeper) hasSubmittedUpdate(ctx sdk.Context, channelID types.ChannelID) bool {
+	store := ctx.KVStore(k.storeKey)
+	return store.Has(types.GetSubmittedUpdateKey(channelID))
+}
+
 // Store payment channel struct in blockchain store.
 func (k Keeper) setSubmittedUpdate(ctx sdk.Context, sUpdate types.SubmittedUpdate) {
 	store := ctx.KVStore(k.storeKey)
@@ -316,6 +929,20 @@ func (k Keeper) deleteSubmittedUpdate(ctx sdk.Context, channelID types.ChannelID
 	// TODO does this have return values? What happens when key doesn't exist?
 }
 
+// iterateSubmittedUpdates calls the provided function on every submitted update in the store, stopping early if it returns true.
+func (k Keeper) iterateSubmittedUpdates(ctx sdk.Context, cb func(sUpdate types.SubmittedUpdate) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.SubmittedUpdateKeyPrefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var sUpdate types.SubmittedUpdate
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &sUpdate)
+		if cb(sUpdate) {
+			break
+		}
+	}
+}
+
 // ============================================================
 // CHANNELS
 // ============================================================
@@ -344,37 +971,230 @@ func (k Keeper) setChannel(ctx sdk.Context, channel types.Channel) {
 	// write to db
 	key := types.GetChannelKey(channel.ID)
 	store.Set(key, bz) // panics if something goes wrong
+	// index by sender and receiver, participants never change so existing entries can be overwritten
+	idBz := k.cdc.MustMarshalBinaryLengthPrefixed(channel.ID)
+	for _, sender := range channel.Senders() {
+		store.Set(types.GetChannelsBySenderKey(sender, channel.ID), idBz)
+	}
+	for _, receiver := range channel.Receivers() {
+		store.Set(types.GetChannelsByReceiverKey(receiver, channel.ID), idBz)
+	}
+	// queue for expiry, the expiry never changes so an existing entry can be overwritten
+	if !channel.Expiry.IsZero() {
+		store.Set(types.GetChannelExpiryQueueKey(channel.Expiry, channel.ID), idBz)
+	}
 }
 
-// deleteChannel removes a channel struct from the blockchain store.
+// deleteChannel removes a channel struct and its index entries from the blockchain store.
 func (k Keeper) deleteChannel(ctx sdk.Context, channelID types.ChannelID) {
+	channel, found := k.getChannel(ctx, channelID)
+	if !found {
+		return
+	}
 	store := ctx.KVStore(k.storeKey)
 	store.Delete(types.GetChannelKey(channelID))
-	// TODO does this have return values? What happens when key doesn't exist?
+	for _, sender := range channel.Senders() {
+		store.Delete(types.GetChannelsBySenderKey(sender, channelID))
+	}
+	for _, receiver := range channel.Receivers() {
+		store.Delete(types.GetChannelsByReceiverKey(receiver, channelID))
+	}
+	if !channel.Expiry.IsZero() {
+		store.Delete(types.GetChannelExpiryQueueKey(channel.Expiry, channelID))
+	}
+}
+
+// iterateChannels calls the provided function on every channel in the store, stopping early if it returns true.
+func (k Keeper) iterateChannels(ctx sdk.Context, cb func(channel types.Channel) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.ChannelKeyPrefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var channel types.Channel
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &channel)
+		if cb(channel) {
+			break
+		}
+	}
+}
+
+// iterateChannelsBySender calls the provided function on every channel the address can pay into, in ID order, stopping early if it returns true.
+func (k Keeper) iterateChannelsBySender(ctx sdk.Context, sender sdk.AccAddress, cb func(channel types.Channel) (stop bool)) {
+	k.iterateChannelIndex(ctx, types.GetChannelsBySenderPrefix(sender), cb)
+}
+
+// iterateChannelsByReceiver calls the provided function on every channel that can pay the address, in ID order, stopping early if it returns true.
+func (k Keeper) iterateChannelsByReceiver(ctx sdk.Context, receiver sdk.AccAddress, cb func(channel types.Channel) (stop bool)) {
+	k.iterateChannelIndex(ctx, types.GetChannelsByReceiverPrefix(receiver), cb)
+}
+
+// iterateChannelIndex calls the provided function on the channels with entries under the given index prefix.
+func (k Keeper) iterateChannelIndex(ctx sdk.Context, prefix []byte, cb func(channel types.Channel) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, prefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var channelID types.ChannelID
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &channelID)
+		channel, found := k.getChannel(ctx, channelID)
+		if !found {
+			panic("can't find channel in index that should exist")
+		}
+		if cb(channel) {
+			break
+		}
+	}
+}
+
+// channelExpiryQueueIterator returns an iterator over the expiry queue entries of channels expiring up to and including endTime, earliest first.
+// Values are channel IDs.
+func (k Keeper) channelExpiryQueueIterator(ctx sdk.Context, endTime time.Time) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return store.Iterator(types.ChannelExpiryQueueKeyPrefix, sdk.PrefixEndBytes(types.GetChannelExpiryQueueTimeKey(endTime)))
 }
 
 // getNewChannelID deterministically creates a new id, updating a global counter counter.
 func (k Keeper) getNewChannelID(ctx sdk.Context) types.ChannelID {
-	// get last channel ID
-	var lastID types.ChannelID
+	newID := k.getNextChannelID(ctx)
+	k.setNextChannelID(ctx, newID+1)
+	return newID
+}
+
+// getNextChannelID returns the id that will be given to the next channel, without incrementing the global counter.
+// IDs start from 0 if the counter hasn't been set.
+func (k Keeper) getNextChannelID(ctx sdk.Context) types.ChannelID {
+	var nextID types.ChannelID
 	store := ctx.KVStore(k.storeKey)
-	bz := store.Get(getLastChannelIDKey())
+	bz := store.Get(types.NextChannelIDKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &nextID)
+	}
+	return nextID
+}
+
+// setNextChannelID sets the global counter so the next channel created will have the given id.
+func (k Keeper) setNextChannelID(ctx sdk.Context, nextID types.ChannelID) {
+	store := ctx.KVStore(k.storeKey)
+	bz := k.cdc.MustMarshalBinaryLengthPrefixed(nextID)
+	store.Set(types.NextChannelIDKey, bz)
+}
+
+// ============================================================
+// HASH LOCKS
+// ============================================================
+
+// settleExpiredHashLocks pays out pending hash locks that have expired by the current block height, to the receiver if the preimage was
+// revealed in time, otherwise back to the sender.
+func (k Keeper) settleExpiredHashLocks(ctx sdk.Context) sdk.Tags {
+	tags := sdk.EmptyTags()
+
+	// Collect the expired locks first as the store can't be modified while iterating over it
+	var locks []types.PendingHashLock
+	iter := k.pendingHashLocksIterator(ctx, ctx.BlockHeight())
+	for ; iter.Valid(); iter.Next() {
+		var lock types.PendingHashLock
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &lock)
+		locks = append(locks, lock)
+	}
+	iter.Close()
+
+	for _, lock := range locks {
+		recipient, result := lock.Sender, types.HashLockRefunded
+		if k.isHashLockUnlocked(ctx, lock.HashLock) {
+			recipient, result = lock.Receiver, types.HashLockClaimed
+		}
+		err := k.bankKeeper.SendCoins(ctx, types.ModuleAddress, recipient, lock.Amount)
+		if err != nil {
+			panic(err)
+		}
+		k.deletePendingHashLock(ctx, lock)
+		tags = tags.AppendTags(sdk.NewTags(
+			types.TagCategory, types.TxCategory,
+			types.TagChannelID, lock.ChannelID.String(),
+			types.TagSender, lock.Sender.String(),
+			types.TagReceiver, lock.Receiver.String(),
+			types.TagHash, hex.EncodeToString(lock.Hash),
+			types.TagHashLock, result,
+		))
+	}
+	return tags
+}
+
+// isHashLockUnlocked returns whether a hash lock's preimage was revealed before it expired.
+func (k Keeper) isHashLockUnlocked(ctx sdk.Context, lock types.HashLock) bool {
+	height, found := k.getPreimageRevealHeight(ctx, lock.Hash)
+	return found && height < lock.Expiry
+}
+
+func (k Keeper) getPreimageRevealHeight(ctx sdk.Context, hash []byte) (int64, bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetRevealedPreimageKey(hash))
 	if bz == nil {
-		lastID = -1 // TODO is just setting to zero if uninitialized ok?
-	} else {
-		k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &lastID)
+		return 0, false
 	}
-	// increment to create new one
-	newID := lastID + 1
-	bz = k.cdc.MustMarshalBinaryLengthPrefixed(newID)
-	// set last channel id again
-	store.Set(getLastChannelIDKey(), bz)
-	// return
-	return newID
+	var height int64
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &height)
+	return height, true
+}
+
+func (k Keeper) setPreimageRevealHeight(ctx sdk.Context, hash []byte, height int64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetRevealedPreimageKey(hash), k.cdc.MustMarshalBinaryLengthPrefixed(height))
 }
 
-// getLastChannelIDKey returns the store key used for the global id counter.
-// TODO should this move to types? external parties shouldn't need to query this
-func getLastChannelIDKey() []byte {
-	return []byte("lastChannelID")
+// iterateRevealedPreimages calls the provided function on every revealed preimage record, stopping early if it returns true.
+func (k Keeper) iterateRevealedPreimages(ctx sdk.Context, cb func(revealed types.RevealedPreimage) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.RevealedPreimageKeyPrefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var height int64
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &height)
+		revealed := types.RevealedPreimage{
+			Hash:   iter.Key()[len(types.RevealedPreimageKeyPrefix):],
+			Height: height,
+		}
+		if cb(revealed) {
+			break
+		}
+	}
+}
+
+func (k Keeper) setPendingHashLock(ctx sdk.Context, lock types.PendingHashLock) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetPendingHashLockKey(lock.Expiry, lock.ChannelID, lock.Index), k.cdc.MustMarshalBinaryLengthPrefixed(lock))
+}
+
+func (k Keeper) deletePendingHashLock(ctx sdk.Context, lock types.PendingHashLock) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetPendingHashLockKey(lock.Expiry, lock.ChannelID, lock.Index))
+}
+
+// pendingHashLocksIterator returns an iterator over the pending hash locks expiring at or before endHeight, earliest first.
+func (k Keeper) pendingHashLocksIterator(ctx sdk.Context, endHeight int64) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return store.Iterator(types.PendingHashLocksKeyPrefix, sdk.PrefixEndBytes(types.GetPendingHashLocksExpiryKey(endHeight)))
+}
+
+// iteratePendingHashLocks calls the provided function on every pending hash lock, in expiry order, stopping early if it returns true.
+func (k Keeper) iteratePendingHashLocks(ctx sdk.Context, cb func(lock types.PendingHashLock) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.PendingHashLocksKeyPrefix)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var lock types.PendingHashLock
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iter.Value(), &lock)
+		if cb(lock) {
+			break
+		}
+	}
+}
+
+// ============================================================
+// ESCROW
+// ============================================================
+
+// getEscrowedCoins returns the coins held by the module account, ie all coins locked up in channels.
+func (k Keeper) getEscrowedCoins(ctx sdk.Context) sdk.Coins {
+	return k.bankKeeper.GetCoins(ctx, types.ModuleAddress)
 }
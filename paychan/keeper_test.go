@@ -1,10 +1,15 @@
 package paychan
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"testing"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/multisig"
 
 	"github.com/kava-labs/cosmos-paychan/paychan/types"
 )
@@ -26,6 +31,7 @@ func TestKeeper(t *testing.T) {
 			sender              sdk.AccAddress
 			receiver            sdk.AccAddress
 			coins               sdk.Coins
+			disputePeriod       time.Duration
 			shouldCreateChannel bool
 			shouldError         bool
 		}{
@@ -34,14 +40,43 @@ func TestKeeper(t *testing.T) {
 				addrs[senderAccountIndex],
 				addrs[receiverAccountIndex],
 				sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+				0,
 				true,
 				false,
 			},
+			{
+				"CustomDisputePeriod",
+				addrs[senderAccountIndex],
+				addrs[receiverAccountIndex],
+				sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+				types.DefaultParams().MinDisputePeriod,
+				true,
+				false,
+			},
+			{
+				"DisputePeriodTooShort",
+				addrs[senderAccountIndex],
+				addrs[receiverAccountIndex],
+				sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+				types.DefaultParams().MinDisputePeriod - time.Second,
+				false,
+				true,
+			},
+			{
+				"DisputePeriodTooLong",
+				addrs[senderAccountIndex],
+				addrs[receiverAccountIndex],
+				sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+				types.DefaultParams().MaxDisputePeriod + time.Second,
+				false,
+				true,
+			},
 			{
 				"NilAddress",
 				sdk.AccAddress{},
 				sdk.AccAddress{},
 				sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+				0,
 				false,
 				true,
 			},
@@ -50,6 +85,7 @@ func TestKeeper(t *testing.T) {
 				addrs[senderAccountIndex],
 				addrs[receiverAccountIndex],
 				sdk.Coins{},
+				0,
 				false,
 				true,
 			},
@@ -62,7 +98,7 @@ func TestKeeper(t *testing.T) {
 				ctx, coinKeeper, channelKeeper, addrs, _, _, genAccFunding := createMockApp(accountSeeds)
 				//
 				////// ACTION
-				_, err := channelKeeper.CreateChannel(ctx, testCase.sender, testCase.receiver, testCase.coins)
+				_, err := channelKeeper.CreateChannel(ctx, testCase.sender, testCase.receiver, testCase.coins, testCase.disputePeriod, nil, nil, time.Time{})
 
 				//
 				////// CHECK RESULTS
@@ -77,10 +113,15 @@ func TestKeeper(t *testing.T) {
 				createdChan, found := channelKeeper.getChannel(ctx, channelID)
 
 				if testCase.shouldCreateChannel {
+					expectedDisputePeriod := testCase.disputePeriod
+					if expectedDisputePeriod == 0 {
+						expectedDisputePeriod = types.DefaultParams().DefaultDisputePeriod
+					}
 					expectedChan := types.Channel{
-						ID:           channelID,
-						Participants: [2]sdk.AccAddress{testCase.sender, testCase.receiver},
-						Coins:        testCase.coins,
+						ID:            channelID,
+						Participants:  []sdk.AccAddress{testCase.sender, testCase.receiver},
+						Coins:         testCase.coins,
+						DisputePeriod: expectedDisputePeriod,
 					}
 
 					// channel exists and correct
@@ -127,10 +168,12 @@ func TestKeeper(t *testing.T) {
 		channelID := types.ChannelID(0) // should be 0 as first channel
 		channel := types.Channel{
 			ID:           channelID,
-			Participants: [2]sdk.AccAddress{addrs[senderAccountIndex], addrs[receiverAccountIndex]},
+			Participants: []sdk.AccAddress{addrs[senderAccountIndex], addrs[receiverAccountIndex]},
 			Coins:        coins,
 		}
 		channelKeeper.setChannel(ctx, channel)
+		// lock the channel coins in the module account
+		coinKeeper.AddCoins(ctx, types.ModuleAddress, coins)
 
 		// create closing update
 		payout := types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}
@@ -140,14 +183,11 @@ func TestKeeper(t *testing.T) {
 			// empty sig
 		}
 		cryptoSig, _ := privKeys[senderAccountIndex].Sign(update.GetSignBytes())
-		update.Sigs = [1]types.UpdateSignature{{
+		update.Sigs = []types.UpdateSignature{{
 			PubKey:          pubKeys[senderAccountIndex],
 			CryptoSignature: cryptoSig,
 		}}
 
-		// Set empty submittedUpdatesQueue TODO work out proper genesis initialisation
-		channelKeeper.setSubmittedUpdatesQueue(ctx, types.SubmittedUpdatesQueue{})
-
 		// ACTION
 		_, err := channelKeeper.CloseChannelByReceiver(ctx, update)
 
@@ -189,74 +229,74 @@ func TestKeeper(t *testing.T) {
 			name                    string
 			setupChannel            bool
 			updateToSubmit          testUpdate
-			expectedSubmittedUpdate string // "empty" or "sameAsSubmitted"
-			shouldError             bool
+			expectedSubmittedUpdate string       // "empty" or "sameAsSubmitted"
+			expectedCode            sdk.CodeType // CodeOK if no error expected
 		}{
 			{
 				"HappyPath",
 				true,
 				testUpdate{chanID, types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}, senderAccountIndex, senderAccountIndex},
-				"sameAsSubmited",
-				false,
+				"sameAsSubmitted",
+				sdk.CodeOK,
 			},
 			{
 				"NoChannel",
 				false,
 				testUpdate{chanID, types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}, senderAccountIndex, senderAccountIndex},
 				"empty",
-				true,
+				types.CodeUnknownChannel,
 			},
 			{
 				"NoCoins",
 				true,
 				testUpdate{chanID, types.Payout{sdk.Coins{}}, senderAccountIndex, senderAccountIndex},
 				"empty",
-				true,
+				types.CodeInvalidPayout,
 			},
 			{
 				"TooManyCoins",
 				true,
 				testUpdate{chanID, types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 100)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}, senderAccountIndex, senderAccountIndex},
 				"empty",
-				true,
+				types.CodePayoutMismatch,
 			},
 			{
 				"WrongSignature",
 				true,
 				testUpdate{chanID, types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}, senderAccountIndex, otherAccountIndex},
 				"empty",
-				true,
+				types.CodeInvalidSignature,
 			},
 			{
 				"WrongPubKey",
 				true,
 				testUpdate{chanID, types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}, otherAccountIndex, senderAccountIndex},
 				"empty",
-				true,
+				types.CodeInvalidSignature,
 			},
 			{
 				"ReceiverSigned",
 				true,
 				testUpdate{chanID, types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}, receiverAccountIndex, receiverAccountIndex},
 				"empty",
-				true,
+				types.CodeInvalidSignature,
 			},
 		}
 		for _, testCase := range testCases {
 			t.Run(testCase.name, func(t *testing.T) {
 
 				// SETUP
-				ctx, _, channelKeeper, addrs, pubKeys, privKeys, _ := createMockApp(accountSeeds)
-				// Set empty submittedUpdatesQueue TODO work out proper genesis initialisation
-				channelKeeper.setSubmittedUpdatesQueue(ctx, types.SubmittedUpdatesQueue{})
+				ctx, coinKeeper, channelKeeper, addrs, pubKeys, privKeys, _ := createMockApp(accountSeeds)
 				// create new channel
 				if testCase.setupChannel {
 					channel := types.Channel{
-						ID:           chanID, // should be 0 as first channel
-						Participants: [2]sdk.AccAddress{addrs[senderAccountIndex], addrs[receiverAccountIndex]},
-						Coins:        sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+						ID:            chanID, // should be 0 as first channel
+						Participants:  []sdk.AccAddress{addrs[senderAccountIndex], addrs[receiverAccountIndex]},
+						Coins:         sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+						DisputePeriod: time.Hour,
 					}
 					channelKeeper.setChannel(ctx, channel)
+					coinKeeper.AddCoins(ctx, types.ModuleAddress, channel.Coins)
 				}
 
 				// create update
@@ -268,7 +308,7 @@ func TestKeeper(t *testing.T) {
 				}
 				// create update's signature
 				cryptoSig, _ := privKeys[testCase.updateToSubmit.sigAccountIndex].Sign(updateToSubmit.GetSignBytes())
-				updateToSubmit.Sigs = [1]types.UpdateSignature{{
+				updateToSubmit.Sigs = []types.UpdateSignature{{
 					PubKey:          pubKeys[testCase.updateToSubmit.pubKeyAccountIndex],
 					CryptoSignature: cryptoSig,
 				}}
@@ -278,10 +318,12 @@ func TestKeeper(t *testing.T) {
 
 				// CHECK RESULTS
 				// Check error
-				if testCase.shouldError {
-					assert.Error(t, err)
-				} else {
+				if testCase.expectedCode == sdk.CodeOK {
 					assert.NoError(t, err)
+				} else {
+					require.Error(t, err)
+					assert.Equal(t, types.DefaultCodespace, err.Codespace())
+					assert.Equal(t, testCase.expectedCode, err.Code())
 				}
 				// Check submittedUpdate
 				su, found := channelKeeper.getSubmittedUpdate(ctx, chanID)
@@ -291,7 +333,7 @@ func TestKeeper(t *testing.T) {
 					assert.Zero(t, su)
 				case "sameAsSubmitted":
 					assert.True(t, found)
-					expectedSU := types.SubmittedUpdate{updateToSubmit, types.ChannelDisputeTime}
+					expectedSU := types.SubmittedUpdate{updateToSubmit, time.Time{}.Add(time.Hour)}
 					assert.Equal(t, expectedSU, su)
 				}
 
@@ -300,4 +342,961 @@ func TestKeeper(t *testing.T) {
 
 	})
 
+	t.Run("DisputePeriodParam", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, _, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+		blockTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+		ctx = ctx.WithBlockTime(blockTime)
+		params := types.NewParams(2*time.Hour, time.Hour, 3*time.Hour, sdk.OneDec())
+		channelKeeper.SetParams(ctx, params)
+		_, err := channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], usd(10), 0, nil, nil, time.Time{})
+		assert.NoError(t, err)
+
+		// ACTION
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(3, 7), 0, privKeys[0]))
+
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		assert.Equal(t, params, channelKeeper.GetParams(ctx))
+		su, found := channelKeeper.getSubmittedUpdate(ctx, 0)
+		assert.True(t, found)
+		assert.Equal(t, blockTime.Add(2*time.Hour), su.ExecutionTime)
+	})
+	t.Run("InvalidDisputePeriodParams", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			params types.Params
+		}{
+			{"zeroMin", types.NewParams(0, 0, 3*time.Hour, sdk.OneDec())},
+			{"negativeMin", types.NewParams(time.Hour, -time.Hour, 3*time.Hour, sdk.OneDec())},
+			{"maxBelowMin", types.NewParams(2*time.Hour, 2*time.Hour, time.Hour, sdk.OneDec())},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// SETUP params set by a param change proposal are not validated
+				accountSeeds := []string{"senderSeed", "receiverSeed"}
+				ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+				channelKeeper.SetParams(ctx, testCase.params)
+
+				// ACTION
+				_, err := channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], sdk.Coins{sdk.NewInt64Coin("usd", 10)}, time.Hour, nil, nil, time.Time{})
+
+				// CHECK RESULTS
+				require.NotNil(t, err)
+				assert.Equal(t, types.CodeInvalidDisputePeriod, err.Code())
+			})
+		}
+	})
+
+	t.Run("Deposit", func(t *testing.T) {
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		const (
+			senderAccountIndex   int = 0
+			receiverAccountIndex int = 1
+		)
+		chanID := types.ChannelID(0)
+
+		testCases := []struct {
+			name           string
+			setupChannel   bool
+			closePending   bool
+			depositorIndex int
+			coins          sdk.Coins
+			shouldError    bool
+		}{
+			{"HappyPath", true, false, senderAccountIndex, sdk.Coins{sdk.NewInt64Coin("usd", 5)}, false},
+			{"NewDenom", true, false, senderAccountIndex, sdk.Coins{sdk.NewInt64Coin("eur", 5)}, true}, // sender has no eur
+			{"NoChannel", false, false, senderAccountIndex, sdk.Coins{sdk.NewInt64Coin("usd", 5)}, true},
+			{"ReceiverDeposits", true, false, receiverAccountIndex, sdk.Coins{sdk.NewInt64Coin("usd", 5)}, true},
+			{"NoCoins", true, false, senderAccountIndex, sdk.Coins{}, true},
+			{"TooManyCoins", true, false, senderAccountIndex, sdk.Coins{sdk.NewInt64Coin("usd", 5000)}, true},
+			{"ClosePending", true, true, senderAccountIndex, sdk.Coins{sdk.NewInt64Coin("usd", 5)}, true},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// SETUP
+				ctx, coinKeeper, channelKeeper, addrs, _, _, genAccFunding := createMockApp(accountSeeds)
+				channelCoins := sdk.Coins{sdk.NewInt64Coin("usd", 10)}
+				if testCase.setupChannel {
+					_, err := channelKeeper.CreateChannel(ctx, addrs[senderAccountIndex], addrs[receiverAccountIndex], channelCoins, 0, nil, nil, time.Time{})
+					assert.NoError(t, err)
+				}
+				if testCase.closePending {
+					channelKeeper.addToSubmittedUpdatesQueue(ctx, types.SubmittedUpdate{
+						Update: types.Update{ChannelID: chanID, Payout: types.Payout{channelCoins, sdk.Coins{}}},
+					})
+				}
+				depositor := addrs[testCase.depositorIndex]
+				depositorCoins := coinKeeper.GetCoins(ctx, depositor)
+
+				// ACTION
+				_, err := channelKeeper.Deposit(ctx, chanID, depositor, testCase.coins)
+
+				// CHECK RESULTS
+				channel, found := channelKeeper.getChannel(ctx, chanID)
+				if testCase.shouldError {
+					assert.Error(t, err)
+					assert.Equal(t, depositorCoins, coinKeeper.GetCoins(ctx, depositor))
+					if found {
+						assert.Equal(t, channelCoins, channel.Coins)
+					}
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, channelCoins.Add(testCase.coins), channel.Coins)
+					assert.Equal(t, genAccFunding.Sub(channelCoins).Sub(testCase.coins), coinKeeper.GetCoins(ctx, depositor))
+				}
+			})
+		}
+	})
+
+	t.Run("CloseAfterDeposit", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, coinKeeper, channelKeeper, addrs, _, privKeys, genAccFunding := createMockApp(accountSeeds)
+		_, err := channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], usd(10), 0, nil, nil, time.Time{})
+		assert.NoError(t, err)
+		// sign an update for the original channel amount
+		update := signedUpdate(0, usdPayout(3, 7), 0, privKeys[0])
+		// top up the channel
+		_, err = channelKeeper.Deposit(ctx, 0, addrs[0], usd(5))
+		assert.NoError(t, err)
+
+		// ACTION
+		_, err = channelKeeper.CloseChannelByReceiver(ctx, update)
+
+		// CHECK RESULTS
+		// old update still valid, with the deposit returned to the sender
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Sub(usd(7)), coinKeeper.GetCoins(ctx, addrs[0]))
+		assert.Equal(t, genAccFunding.Add(usd(7)), coinKeeper.GetCoins(ctx, addrs[1]))
+	})
+
+	t.Run("Withdraw", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, coinKeeper, channelKeeper, addrs, _, privKeys, genAccFunding := createMockApp(accountSeeds)
+		sender, receiver := addrs[0], addrs[1]
+		_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+		assert.NoError(t, err)
+
+		// ACTION
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[0]))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Add(usd(4)), coinKeeper.GetCoins(ctx, receiver))
+		channel, found := channelKeeper.getChannel(ctx, 0)
+		assert.True(t, found)
+		assert.Equal(t, usd(6), channel.Coins)
+		assert.Equal(t, usd(4), channel.Withdrawn)
+
+		// ACTION withdraw again with the same update
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[0]))
+		// CHECK RESULTS nothing to withdraw
+		require.Error(t, err)
+		assert.Equal(t, types.CodeNothingToWithdraw, err.Code())
+
+		// ACTION withdraw a later update
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(3, 7), 0, privKeys[0]))
+		// CHECK RESULTS only the difference is paid
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Add(usd(7)), coinKeeper.GetCoins(ctx, receiver))
+		channel, _ = channelKeeper.getChannel(ctx, 0)
+		assert.Equal(t, usd(3), channel.Coins)
+		assert.Equal(t, usd(7), channel.Withdrawn)
+
+		// ACTION sender tries to close with an update from before the withdrawal
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[0]))
+		// CHECK RESULTS
+		require.Error(t, err)
+		assert.Equal(t, types.CodePayoutMismatch, err.Code())
+
+		// ACTION sender closes with the latest update
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(2, 8), 0, privKeys[0]))
+		assert.NoError(t, err)
+		// CHECK RESULTS can't withdraw while closing
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(1, 9), 0, privKeys[0]))
+		require.Error(t, err)
+		assert.Equal(t, types.CodeChannelClosing, err.Code())
+
+		// ACTION receiver closes
+		_, err = channelKeeper.CloseChannelByReceiver(ctx, signedUpdate(0, usdPayout(1, 9), 0, privKeys[0]))
+		// CHECK RESULTS remaining funds paid out without double paying withdrawn coins
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Sub(usd(9)), coinKeeper.GetCoins(ctx, sender))
+		assert.Equal(t, genAccFunding.Add(usd(9)), coinKeeper.GetCoins(ctx, receiver))
+		_, found = channelKeeper.getChannel(ctx, 0)
+		assert.False(t, found)
+	})
+
+	t.Run("Bidirectional", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"aliceSeed", "bobSeed"}
+		ctx, coinKeeper, channelKeeper, addrs, _, privKeys, genAccFunding := createMockApp(accountSeeds)
+		ctx = ctx.WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+		alice, bob := addrs[0], addrs[1]
+
+		// ACTION
+		_, err := channelKeeper.CreateMultipartyChannel(ctx, []sdk.AccAddress{alice, bob}, types.Payout{usd(10), usd(5)}, 0, time.Hour)
+		// CHECK RESULTS both participants fund the channel
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Sub(usd(10)), coinKeeper.GetCoins(ctx, alice))
+		assert.Equal(t, genAccFunding.Sub(usd(5)), coinKeeper.GetCoins(ctx, bob))
+		channel, found := channelKeeper.getChannel(ctx, 0)
+		assert.True(t, found)
+		assert.True(t, channel.Multiparty)
+		assert.Equal(t, usd(15), channel.Coins)
+
+		// CHECK RESULTS unidirectional actions aren't allowed
+		_, err = channelKeeper.Deposit(ctx, 0, alice, usd(1))
+		assert.Error(t, err)
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(5, 10), 1, privKeys[0], privKeys[1]))
+		assert.Error(t, err)
+		_, err = channelKeeper.CloseChannelByReceiver(ctx, signedUpdate(0, usdPayout(5, 10), 1, privKeys[0], privKeys[1]))
+		assert.Error(t, err)
+
+		// ACTION dispute with an update signed by only one participant
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(5, 10), 1, privKeys[0]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION dispute with an update not paying out the whole channel
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(5, 5), 1, privKeys[0], privKeys[1]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION dispute with an old update
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(12, 3), 1, privKeys[1], privKeys[0]))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		sUpdate, found := channelKeeper.getSubmittedUpdate(ctx, 0)
+		assert.True(t, found)
+		executionTime := ctx.BlockHeader().Time.Add(time.Hour)
+		assert.Equal(t, executionTime, sUpdate.ExecutionTime)
+
+		// ACTION counter with an update with the same or lower sequence
+		laterCtx := ctx.WithBlockTime(ctx.BlockHeader().Time.Add(30 * time.Minute))
+		_, err = channelKeeper.InitCloseChannelBySender(laterCtx, signedUpdate(0, usdPayout(5, 10), 1, privKeys[0], privKeys[1]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+		_, err = channelKeeper.InitCloseChannelBySender(laterCtx, signedUpdate(0, usdPayout(5, 10), 0, privKeys[0], privKeys[1]))
+		assert.Error(t, err)
+
+		// ACTION counter with a newer update
+		_, err = channelKeeper.InitCloseChannelBySender(laterCtx, signedUpdate(0, usdPayout(4, 11), 2, privKeys[0], privKeys[1]))
+		// CHECK RESULTS update replaced without extending the dispute period
+		assert.NoError(t, err)
+		sUpdate, _ = channelKeeper.getSubmittedUpdate(laterCtx, 0)
+		assert.Equal(t, uint64(2), sUpdate.Sequence)
+		assert.Equal(t, executionTime, sUpdate.ExecutionTime)
+
+		// ACTION counter once the dispute period has ended, in the block the close executes in
+		_, err = channelKeeper.InitCloseChannelBySender(ctx.WithBlockTime(executionTime), signedUpdate(0, usdPayout(4, 11), 3, privKeys[0], privKeys[1]))
+		// CHECK RESULTS
+		require.NotNil(t, err)
+		assert.Equal(t, types.CodeChannelClosing, err.Code())
+
+		// ACTION counter just before the dispute period ends
+		_, err = channelKeeper.InitCloseChannelBySender(ctx.WithBlockTime(executionTime.Add(-time.Nanosecond)), signedUpdate(0, usdPayout(4, 11), 3, privKeys[0], privKeys[1]))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		sUpdate, _ = channelKeeper.getSubmittedUpdate(ctx, 0)
+		assert.Equal(t, uint64(3), sUpdate.Sequence)
+
+		// ACTION
+		EndBlocker(ctx.WithBlockTime(executionTime), channelKeeper)
+		// CHECK RESULTS newest update paid out
+		_, found = channelKeeper.getChannel(ctx, 0)
+		assert.False(t, found)
+		assert.Equal(t, genAccFunding.Sub(usd(6)), coinKeeper.GetCoins(ctx, alice))
+		assert.Equal(t, genAccFunding.Add(usd(6)), coinKeeper.GetCoins(ctx, bob))
+	})
+
+	t.Run("CooperativeClose", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed", "otherSeed"}
+		ctx, coinKeeper, channelKeeper, addrs, _, privKeys, genAccFunding := createMockApp(accountSeeds)
+		sender, receiver := addrs[0], addrs[1]
+		_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+		assert.NoError(t, err)
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(8, 2), 0, privKeys[0]))
+		assert.NoError(t, err)
+
+		// ACTION close with only the sender's signature
+		_, err = channelKeeper.CooperativeCloseChannel(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[0]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION close with a signature from a non participant
+		_, err = channelKeeper.CooperativeCloseChannel(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[0], privKeys[2]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION close with both signatures
+		_, err = channelKeeper.CooperativeCloseChannel(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[1], privKeys[0]))
+		// CHECK RESULTS channel settled immediately and the pending close removed
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Sub(usd(4)), coinKeeper.GetCoins(ctx, sender))
+		assert.Equal(t, genAccFunding.Add(usd(4)), coinKeeper.GetCoins(ctx, receiver))
+		_, found := channelKeeper.getChannel(ctx, 0)
+		assert.False(t, found)
+		_, found = channelKeeper.getSubmittedUpdate(ctx, 0)
+		assert.False(t, found)
+		assert.False(t, channelKeeper.hasSubmittedUpdate(ctx, 0))
+	})
+
+	t.Run("Multiparty", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"aliceSeed", "bobSeed", "carolSeed", "daveSeed"}
+		ctx, coinKeeper, channelKeeper, addrs, _, privKeys, genAccFunding := createMockApp(accountSeeds)
+		participants := addrs[:3]
+
+		// ACTION
+		_, err := channelKeeper.CreateMultipartyChannel(ctx, participants, types.Payout{usd(10), usd(20)}, 2, 0)
+		// CHECK RESULTS a deposit is needed for each participant
+		assert.Error(t, err)
+
+		// ACTION
+		_, err = channelKeeper.CreateMultipartyChannel(ctx, participants, types.Payout{usd(10), usd(20), sdk.Coins{}}, 4, 0)
+		// CHECK RESULTS threshold can't exceed participants
+		assert.Error(t, err)
+
+		// ACTION
+		_, err = channelKeeper.CreateMultipartyChannel(ctx, participants, types.Payout{usd(10), usd(20), sdk.Coins{}}, 2, 0)
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		channel, found := channelKeeper.getChannel(ctx, 0)
+		assert.True(t, found)
+		assert.Equal(t, usd(30), channel.Coins)
+		assert.Equal(t, uint64(2), channel.SigThreshold)
+
+		// ACTION update signed below the threshold, or by a non participant
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(5, 15, 10), 1, privKeys[2]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(5, 15, 10), 1, privKeys[2], privKeys[3]))
+		assert.Error(t, err)
+		// CHECK RESULTS the same participant signing twice doesn't count twice
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(5, 15, 10), 1, privKeys[2], privKeys[2]))
+		assert.Error(t, err)
+
+		// ACTION update with a payout for the wrong number of participants
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(15, 15), 1, privKeys[0], privKeys[1]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION update signed by a threshold of participants
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(5, 15, 10), 1, privKeys[2], privKeys[0]))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+
+		// ACTION cooperative close needs all participants
+		_, err = channelKeeper.CooperativeCloseChannel(ctx, signedUpdate(0, usdPayout(4, 16, 10), 2, privKeys[0], privKeys[1]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+		_, err = channelKeeper.CooperativeCloseChannel(ctx, signedUpdate(0, usdPayout(4, 16, 10), 2, privKeys[0], privKeys[1], privKeys[2]))
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Sub(usd(6)), coinKeeper.GetCoins(ctx, participants[0]))
+		assert.Equal(t, genAccFunding.Sub(usd(4)), coinKeeper.GetCoins(ctx, participants[1]))
+		assert.Equal(t, genAccFunding.Add(usd(10)), coinKeeper.GetCoins(ctx, participants[2]))
+		_, found = channelKeeper.getChannel(ctx, 0)
+		assert.False(t, found)
+	})
+
+	t.Run("SigningKey", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed", "channelKeySeed"}
+		ctx, coinKeeper, channelKeeper, addrs, pubKeys, privKeys, genAccFunding := createMockApp(accountSeeds)
+		sender, receiver := addrs[0], addrs[1]
+
+		// ACTION
+		_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, pubKeys[2], nil, time.Time{})
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		channel, _ := channelKeeper.getChannel(ctx, 0)
+		assert.Equal(t, pubKeys[2], channel.SigningPubKey)
+
+		// ACTION update signed with the sender's account key
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[0]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION update signed with the channel signing key
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[2]))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+
+		// ACTION cooperative close signed by the receiver and the sender's account key
+		_, err = channelKeeper.CooperativeCloseChannel(ctx, signedUpdate(0, usdPayout(5, 5), 0, privKeys[0], privKeys[1]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION cooperative close signed by the receiver and the channel signing key
+		_, err = channelKeeper.CooperativeCloseChannel(ctx, signedUpdate(0, usdPayout(5, 5), 0, privKeys[2], privKeys[1]))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		assert.Equal(t, genAccFunding.Sub(usd(5)), coinKeeper.GetCoins(ctx, sender))
+		assert.Equal(t, genAccFunding.Add(usd(5)), coinKeeper.GetCoins(ctx, receiver))
+	})
+
+	t.Run("MultisigSender", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"receiverSeed", "keySeed1", "keySeed2", "keySeed3"}
+		ctx, coinKeeper, channelKeeper, addrs, pubKeys, privKeys, genAccFunding := createMockApp(accountSeeds)
+		receiver := addrs[0]
+		multisigPubKey := multisig.NewPubKeyMultisigThreshold(2, pubKeys[1:])
+		sender := sdk.AccAddress(multisigPubKey.Address())
+		_, err := coinKeeper.AddCoins(ctx, sender, usd(100))
+		require.NoError(t, err)
+		// multisignedUpdate signs an update with the given keys, combining their signatures into a multisignature
+		multisignedUpdate := func(senderAmount, receiverAmount int64, signers ...int) types.Update {
+			update := types.Update{
+				ChannelID: 0,
+				Payout:    usdPayout(senderAmount, receiverAmount),
+			}
+			multisignature := multisig.NewMultisig(len(pubKeys[1:]))
+			for _, i := range signers {
+				cryptoSig, _ := privKeys[i].Sign(update.GetSignBytes())
+				require.NoError(t, multisignature.AddSignatureFromPubKey(cryptoSig, pubKeys[i], pubKeys[1:]))
+			}
+			update.Sigs = []types.UpdateSignature{{
+				PubKey:          multisigPubKey,
+				CryptoSignature: multisignature.Marshal(),
+			}}
+			return update
+		}
+		_, err = channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+		require.NoError(t, err)
+
+		// ACTION update signed by fewer keys than the threshold
+		_, err = channelKeeper.Withdraw(ctx, multisignedUpdate(8, 2, 1))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION update signed by one of the keys alone
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(8, 2), 0, privKeys[1]))
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION malformed multisignature, with more bits set than signatures
+		update := multisignedUpdate(8, 2, 1, 3)
+		multisignature := multisig.NewMultisig(len(pubKeys[1:]))
+		for i := 0; i < 2; i++ {
+			cryptoSig, _ := privKeys[i+1].Sign(update.GetSignBytes())
+			multisignature.AddSignature(cryptoSig, i)
+		}
+		multisignature.BitArray.SetIndex(2, true)
+		update.Sigs[0].CryptoSignature = multisignature.Marshal()
+		_, err = channelKeeper.Withdraw(ctx, update)
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION update signed by the threshold of keys
+		_, err = channelKeeper.Withdraw(ctx, multisignedUpdate(8, 2, 1, 3))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+
+		// ACTION
+		_, err = channelKeeper.CloseChannelByReceiver(ctx, multisignedUpdate(6, 4, 2, 3))
+		// CHECK RESULTS
+		assert.NoError(t, err)
+		assert.Equal(t, usd(100-10+6), coinKeeper.GetCoins(ctx, sender))
+		assert.Equal(t, genAccFunding.Add(usd(4)), coinKeeper.GetCoins(ctx, receiver))
+	})
+
+	t.Run("RotateSigningKey", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed", "oldKeySeed", "newKeySeed"}
+		ctx, coinKeeper, channelKeeper, addrs, pubKeys, privKeys, genAccFunding := createMockApp(accountSeeds)
+		ctx = ctx.WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+		sender, receiver := addrs[0], addrs[1]
+		for i := 0; i < 2; i++ {
+			_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), time.Hour, pubKeys[2], nil, time.Time{})
+			assert.NoError(t, err)
+		}
+		// start closing channel 1 with the old key
+		_, err := channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(1, usdPayout(7, 3), 0, privKeys[2]))
+		assert.NoError(t, err)
+
+		// ACTION rotate from an account other than the sender
+		_, err = channelKeeper.RotateSigningKey(ctx, 0, receiver, pubKeys[3])
+		// CHECK RESULTS
+		assert.Error(t, err)
+
+		// ACTION
+		for _, id := range []types.ChannelID{0, 1} {
+			_, err = channelKeeper.RotateSigningKey(ctx, id, sender, pubKeys[3])
+			assert.NoError(t, err)
+		}
+		// CHECK RESULTS
+		channel, _ := channelKeeper.getChannel(ctx, 0)
+		assert.Equal(t, pubKeys[3], channel.SigningPubKey)
+		// updates signed with the old key rejected
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[2]))
+		assert.Error(t, err)
+		// updates signed with the new key accepted
+		_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(6, 4), 0, privKeys[3]))
+		assert.NoError(t, err)
+		// pending close signed with the old key still settles
+		EndBlocker(ctx.WithBlockTime(ctx.BlockHeader().Time.Add(time.Hour)), channelKeeper)
+		_, found := channelKeeper.getChannel(ctx, 1)
+		assert.False(t, found)
+		assert.Equal(t, genAccFunding.Sub(usd(20)).Add(usd(7)), coinKeeper.GetCoins(ctx, sender))
+		assert.Equal(t, genAccFunding.Add(usd(4)).Add(usd(3)), coinKeeper.GetCoins(ctx, receiver))
+	})
+	t.Run("OverrideAfterRotation", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed", "oldKeySeed", "newKeySeed"}
+		ctx, coinKeeper, channelKeeper, addrs, pubKeys, privKeys, genAccFunding := createMockApp(accountSeeds)
+		sender, receiver := addrs[0], addrs[1]
+		_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), time.Hour, pubKeys[2], nil, time.Time{})
+		require.NoError(t, err)
+		// the receiver holds a payment signed with the old key, the sender closes with an older one then rotates
+		receiverUpdate := signedUpdate(0, usdPayout(4, 6), 0, privKeys[2])
+		_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(8, 2), 0, privKeys[2]))
+		require.NoError(t, err)
+		_, err = channelKeeper.RotateSigningKey(ctx, 0, sender, pubKeys[3])
+		require.NoError(t, err)
+
+		// ACTION receiver overrides with the payment signed by the old key
+		_, sdkErr := channelKeeper.CloseChannelByReceiver(ctx, receiverUpdate)
+
+		// CHECK RESULTS old key signatures are rejected
+		require.NotNil(t, sdkErr)
+		assert.Equal(t, types.CodeInvalidSignature, sdkErr.Code())
+
+		// ACTION receiver overrides with the payment re-signed with the new key
+		_, sdkErr = channelKeeper.CloseChannelByReceiver(ctx, signedUpdate(0, usdPayout(4, 6), 0, privKeys[3]))
+
+		// CHECK RESULTS
+		assert.Nil(t, sdkErr)
+		assert.Equal(t, genAccFunding.Sub(usd(6)), coinKeeper.GetCoins(ctx, sender))
+		assert.Equal(t, genAccFunding.Add(usd(6)), coinKeeper.GetCoins(ctx, receiver))
+		assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+	})
+
+	t.Run("PenaltyBond", func(t *testing.T) {
+		var testCases = []struct {
+			name                  string
+			penaltyBond           sdk.Coins
+			penaltyShare          sdk.Dec
+			senderPayout          []int64 // nil for no close by sender
+			receiverPayout        []int64
+			expectedSenderCoins   sdk.Coins
+			expectedReceiverCoins sdk.Coins
+		}{
+			{"noBond", sdk.Coins{}, sdk.OneDec(), []int64{8, 2}, []int64{5, 5}, usd(995), usd(1005)},
+			{"receiverPaidMore", usd(4), sdk.OneDec(), []int64{8, 2}, []int64{5, 5}, usd(991), usd(1009)},
+			{"partialShare", usd(5), sdk.NewDecWithPrec(5, 1), []int64{8, 2}, []int64{5, 5}, usd(993), usd(1007)},
+			{"receiverPaidSame", usd(4), sdk.OneDec(), []int64{5, 5}, []int64{5, 5}, usd(995), usd(1005)},
+			{"noCloseBySender", usd(4), sdk.OneDec(), nil, []int64{5, 5}, usd(995), usd(1005)},
+			{"shareAboveOne", usd(4), sdk.NewDec(2), []int64{8, 2}, []int64{5, 5}, usd(991), usd(1009)}, // param change proposals can set shares outside 0 to 1
+			{"negativeShare", usd(4), sdk.NewDec(-1), []int64{8, 2}, []int64{5, 5}, usd(995), usd(1005)},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// SETUP
+				accountSeeds := []string{"senderSeed", "receiverSeed"}
+				ctx, coinKeeper, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+				sender, receiver := addrs[0], addrs[1]
+				params := channelKeeper.GetParams(ctx)
+				params.PenaltyShare = testCase.penaltyShare
+				channelKeeper.SetParams(ctx, params)
+				_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, testCase.penaltyBond, time.Time{})
+				assert.NoError(t, err)
+				assert.Equal(t, usd(990).Sub(testCase.penaltyBond), coinKeeper.GetCoins(ctx, sender))
+				if testCase.senderPayout != nil {
+					_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(testCase.senderPayout...), 0, privKeys[0]))
+					assert.NoError(t, err)
+				}
+
+				// ACTION
+				_, err = channelKeeper.CloseChannelByReceiver(ctx, signedUpdate(0, usdPayout(testCase.receiverPayout...), 0, privKeys[0]))
+
+				// CHECK RESULTS
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedSenderCoins, coinKeeper.GetCoins(ctx, sender))
+				assert.Equal(t, testCase.expectedReceiverCoins, coinKeeper.GetCoins(ctx, receiver))
+			})
+		}
+	})
+	t.Run("PenaltyBondResolvedHashLock", func(t *testing.T) {
+		preimage := []byte("secret")
+		hash := sha256.Sum256(preimage)
+		var testCases = []struct {
+			name                  string
+			receiverPayout        []int64
+			expectedSenderCoins   sdk.Coins
+			expectedReceiverCoins sdk.Coins
+		}{
+			{"lockMovedIntoPayout", []int64{5, 5}, usd(995), usd(1005)},
+			{"receiverPaidMoreThanLock", []int64{4, 6}, usd(990), usd(1010)},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// SETUP
+				accountSeeds := []string{"senderSeed", "receiverSeed"}
+				ctx, coinKeeper, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+				sender, receiver := addrs[0], addrs[1]
+				_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, usd(4), time.Time{})
+				require.NoError(t, err)
+				// sender closes with a payment still hash locked, the receiver then reveals the preimage
+				senderUpdate := signUpdate(types.Update{
+					ChannelID: 0,
+					Payout:    usdPayout(5, 2),
+					HashLocks: []types.HashLock{{Hash: hash[:], Amount: usd(3), Expiry: 20}},
+				}, privKeys[0])
+				_, err = channelKeeper.InitCloseChannelBySender(ctx, senderUpdate)
+				require.NoError(t, err)
+				_, err = channelKeeper.RevealPreimage(ctx, preimage)
+				require.NoError(t, err)
+
+				// ACTION receiver overrides with a payment where the hash lock has been resolved into the payout
+				_, err = channelKeeper.CloseChannelByReceiver(ctx, signedUpdate(0, usdPayout(testCase.receiverPayout...), 0, privKeys[0]))
+
+				// CHECK RESULTS
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedSenderCoins, coinKeeper.GetCoins(ctx, sender))
+				assert.Equal(t, testCase.expectedReceiverCoins, coinKeeper.GetCoins(ctx, receiver))
+				assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+			})
+		}
+	})
+	t.Run("HashLocks", func(t *testing.T) {
+		preimage := []byte("secret")
+		hash := sha256.Sum256(preimage)
+		var testCases = []struct {
+			name                  string
+			lock                  types.HashLock
+			revealHeight          int64 // 0 for no reveal
+			closeHeight           int64
+			settleHeight          int64 // 0 for no EndBlocker run after the close
+			expectedCode          sdk.CodeType
+			expectedSenderCoins   sdk.Coins
+			expectedReceiverCoins sdk.Coins
+		}{
+			{"revealedBeforeClose", types.HashLock{Hash: hash[:], Amount: usd(3), Expiry: 20}, 10, 10, 0, sdk.CodeOK, usd(995), usd(1005)},
+			{"expiredAtClose", types.HashLock{Hash: hash[:], Amount: usd(3), Expiry: 20}, 0, 20, 0, sdk.CodeOK, usd(998), usd(1002)},
+			{"revealedAfterExpiry", types.HashLock{Hash: hash[:], Amount: usd(3), Expiry: 20}, 20, 20, 0, sdk.CodeOK, usd(998), usd(1002)},
+			{"pendingThenClaimed", types.HashLock{Hash: hash[:], Amount: usd(3), Expiry: 20}, 15, 10, 20, sdk.CodeOK, usd(995), usd(1005)},
+			{"pendingThenRefunded", types.HashLock{Hash: hash[:], Amount: usd(3), Expiry: 20}, 0, 10, 20, sdk.CodeOK, usd(998), usd(1002)},
+			{"exceedsChannel", types.HashLock{Hash: hash[:], Amount: usd(4), Expiry: 20}, 0, 10, 0, types.CodeInvalidHashLock, usd(990), usd(1000)},
+			{"invalidHash", types.HashLock{Hash: preimage, Amount: usd(3), Expiry: 20}, 0, 10, 0, types.CodeInvalidHashLock, usd(990), usd(1000)},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// SETUP
+				accountSeeds := []string{"senderSeed", "receiverSeed"}
+				ctx, coinKeeper, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+				sender, receiver := addrs[0], addrs[1]
+				_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+				require.NoError(t, err)
+				update := signUpdate(types.Update{
+					ChannelID: 0,
+					Payout:    usdPayout(5, 2),
+					HashLocks: []types.HashLock{testCase.lock},
+				}, privKeys[0])
+				reveal := func(height int64) {
+					if testCase.revealHeight == height {
+						_, err := channelKeeper.RevealPreimage(ctx.WithBlockHeight(height), preimage)
+						require.NoError(t, err)
+					}
+				}
+
+				// ACTION
+				reveal(testCase.closeHeight)
+				_, sdkErr := channelKeeper.CloseChannelByReceiver(ctx.WithBlockHeight(testCase.closeHeight), update)
+				if testCase.settleHeight != 0 {
+					reveal(15)
+					EndBlocker(ctx.WithBlockHeight(testCase.settleHeight), channelKeeper)
+				}
+
+				// CHECK RESULTS
+				if testCase.expectedCode == sdk.CodeOK {
+					assert.Nil(t, sdkErr)
+				} else {
+					require.NotNil(t, sdkErr)
+					assert.Equal(t, testCase.expectedCode, sdkErr.Code())
+				}
+				assert.Equal(t, testCase.expectedSenderCoins, coinKeeper.GetCoins(ctx, sender))
+				assert.Equal(t, testCase.expectedReceiverCoins, coinKeeper.GetCoins(ctx, receiver))
+				assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+			})
+		}
+	})
+	t.Run("RevealPreimage", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed"}
+		ctx, _, channelKeeper, _, _, _, _ := createMockApp(accountSeeds)
+		preimage := []byte("secret")
+		hash := sha256.Sum256(preimage)
+
+		// ACTION
+		tags, err := channelKeeper.RevealPreimage(ctx.WithBlockHeight(5), preimage)
+
+		// CHECK RESULTS
+		require.Nil(t, err)
+		assert.Equal(t, sdk.NewTags(types.TagCategory, types.TxCategory, types.TagHash, hex.EncodeToString(hash[:])), tags)
+		height, found := channelKeeper.getPreimageRevealHeight(ctx, hash[:])
+		assert.True(t, found)
+		assert.Equal(t, int64(5), height)
+
+		// ACTION reveal again
+		_, err = channelKeeper.RevealPreimage(ctx.WithBlockHeight(6), preimage)
+
+		// CHECK RESULTS original height is kept
+		require.NotNil(t, err)
+		assert.Equal(t, types.CodePreimageAlreadyRevealed, err.Code())
+		height, _ = channelKeeper.getPreimageRevealHeight(ctx, hash[:])
+		assert.Equal(t, int64(5), height)
+	})
+	t.Run("Expiry", func(t *testing.T) {
+		var testCases = []struct {
+			name                  string
+			senderClose           bool // sender initiates a close before the expiry
+			reclaimer             int  // index of the account reclaiming
+			reclaimTime           time.Duration
+			expectedCode          sdk.CodeType
+			expectedSenderCoins   sdk.Coins
+			expectedReceiverCoins sdk.Coins
+		}{
+			{"HappyPath", false, 0, time.Hour, sdk.CodeOK, usd(995), usd(1005)}, // the penalty bond is returned with the unclaimed coins
+			{"SettlesSenderClose", true, 0, time.Hour, sdk.CodeOK, usd(992), usd(1008)},
+			{"NotExpired", false, 0, time.Hour - time.Second, types.CodeChannelNotExpired, usd(985), usd(1005)},
+			{"NotSender", false, 1, time.Hour, types.CodeWrongSubmitter, usd(985), usd(1005)},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// SETUP
+				accountSeeds := []string{"senderSeed", "receiverSeed"}
+				ctx, coinKeeper, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+				ctx = ctx.WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+				sender, receiver := addrs[0], addrs[1]
+				expiry := ctx.BlockHeader().Time.Add(time.Hour)
+				_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 24*time.Hour, nil, usd(5), expiry)
+				require.NoError(t, err)
+				_, err = channelKeeper.Withdraw(ctx, signedUpdate(0, usdPayout(5, 5), 0, privKeys[0]))
+				require.NoError(t, err)
+				if testCase.senderClose {
+					_, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(0, usdPayout(2, 8), 0, privKeys[0]))
+					require.NoError(t, err)
+				}
+
+				// ACTION
+				_, sdkErr := channelKeeper.ReclaimExpiredChannel(ctx.WithBlockTime(ctx.BlockHeader().Time.Add(testCase.reclaimTime)), 0, addrs[testCase.reclaimer])
+
+				// CHECK RESULTS
+				if testCase.expectedCode == sdk.CodeOK {
+					assert.Nil(t, sdkErr)
+					_, found := channelKeeper.getChannel(ctx, 0)
+					assert.False(t, found)
+				} else {
+					require.NotNil(t, sdkErr)
+					assert.Equal(t, testCase.expectedCode, sdkErr.Code())
+				}
+				assert.Equal(t, testCase.expectedSenderCoins, coinKeeper.GetCoins(ctx, sender))
+				assert.Equal(t, testCase.expectedReceiverCoins, coinKeeper.GetCoins(ctx, receiver))
+				assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+			})
+		}
+	})
+	t.Run("ExpiredChannel", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, _, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+		ctx = ctx.WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+		sender, receiver := addrs[0], addrs[1]
+		update := signedUpdate(0, usdPayout(4, 6), 0, privKeys[0])
+
+		// ACTION
+		_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, ctx.BlockHeader().Time)
+		// CHECK RESULTS expiry must be in the future
+		require.NotNil(t, err)
+		assert.Equal(t, types.CodeInvalidExpiry, err.Code())
+
+		// SETUP
+		_, err = channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, ctx.BlockHeader().Time.Add(time.Hour))
+		require.Nil(t, err)
+		expiredCtx := ctx.WithBlockTime(ctx.BlockHeader().Time.Add(time.Hour))
+
+		// ACTION
+		_, err = channelKeeper.CloseChannelByReceiver(expiredCtx, update)
+		// CHECK RESULTS receiver can no longer claim
+		require.NotNil(t, err)
+		assert.Equal(t, types.CodeChannelExpired, err.Code())
+
+		// ACTION
+		_, err = channelKeeper.Withdraw(expiredCtx, update)
+		// CHECK RESULTS
+		require.NotNil(t, err)
+		assert.Equal(t, types.CodeChannelExpired, err.Code())
+
+		// ACTION
+		_, err = channelKeeper.InitCloseChannelBySender(expiredCtx, update)
+		// CHECK RESULTS
+		require.NotNil(t, err)
+		assert.Equal(t, types.CodeChannelExpired, err.Code())
+
+		// ACTION
+		_, err = channelKeeper.Deposit(expiredCtx, 0, sender, usd(1))
+		// CHECK RESULTS
+		require.NotNil(t, err)
+		assert.Equal(t, types.CodeChannelExpired, err.Code())
+
+		// ACTION
+		_, err = channelKeeper.CloseChannelByReceiver(ctx, update)
+		// CHECK RESULTS receiver can close any time before the expiry
+		assert.Nil(t, err)
+		assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+	})
+	t.Run("BatchClose", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, coinKeeper, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+		sender, receiver := addrs[0], addrs[1]
+		for i := 0; i < 3; i++ {
+			_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+			require.Nil(t, err)
+		}
+		// a channel in the other direction, which the receiver can't close
+		_, err := channelKeeper.CreateChannel(ctx, receiver, sender, usd(10), 0, nil, nil, time.Time{})
+		require.Nil(t, err)
+		updates := []types.Update{
+			signedUpdate(0, usdPayout(7, 3), 0, privKeys[0]),
+			signedUpdate(1, usdPayout(1, 9), 0, privKeys[1]), // signed by the receiver, not the sender
+			signedUpdate(2, usdPayout(4, 6), 0, privKeys[0]),
+			signedUpdate(3, usdPayout(5, 5), 0, privKeys[1]),
+			signedUpdate(9, usdPayout(5, 5), 0, privKeys[0]),
+		}
+
+		// ACTION
+		tags, results := channelKeeper.BatchCloseChannelsByReceiver(ctx, updates, receiver)
+
+		// CHECK RESULTS
+		expectedCodes := []sdk.CodeType{sdk.CodeOK, types.CodeInvalidSignature, sdk.CodeOK, types.CodeWrongSubmitter, types.CodeUnknownChannel}
+		require.Len(t, results, len(updates))
+		for i, result := range results {
+			assert.Equal(t, updates[i].ChannelID, result.ChannelID)
+			assert.Equal(t, expectedCodes[i], result.Code)
+		}
+		for _, id := range []types.ChannelID{0, 2} {
+			_, found := channelKeeper.getChannel(ctx, id)
+			assert.False(t, found)
+			assert.Contains(t, tags, sdk.MakeTag(types.TagChannelID, id.String()))
+		}
+		for _, id := range []types.ChannelID{1, 3} {
+			_, found := channelKeeper.getChannel(ctx, id)
+			assert.True(t, found)
+		}
+		assert.Equal(t, usd(1000-30+7+4), coinKeeper.GetCoins(ctx, sender))
+		assert.Equal(t, usd(1000-10+3+6), coinKeeper.GetCoins(ctx, receiver))
+		assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+	})
+	t.Run("RelayClose", func(t *testing.T) {
+		var testCases = []struct {
+			name                  string
+			signedFee             sdk.Coins
+			submittedFee          sdk.Coins
+			authorizer            int // index of the account signing the authorization
+			expectedCode          sdk.CodeType
+			expectedReceiverCoins sdk.Coins
+			expectedRelayerCoins  sdk.Coins
+		}{
+			{"withFee", usd(1), usd(1), 1, sdk.CodeOK, usd(1005), usd(1001)},
+			{"noFee", sdk.Coins{}, sdk.Coins{}, 1, sdk.CodeOK, usd(1006), usd(1000)},
+			{"wholePayoutAsFee", usd(6), usd(6), 1, sdk.CodeOK, usd(1000), usd(1006)},
+			{"feeTooHigh", usd(7), usd(7), 1, types.CodePayoutMismatch, usd(1000), usd(1000)},
+			{"feeChanged", usd(1), usd(2), 1, types.CodeInvalidSignature, usd(1000), usd(1000)},
+			{"signedBySender", usd(1), usd(1), 0, types.CodeInvalidSignature, usd(1000), usd(1000)},
+			{"signedByRelayer", usd(1), usd(1), 2, types.CodeInvalidSignature, usd(1000), usd(1000)},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// SETUP
+				accountSeeds := []string{"senderSeed", "receiverSeed", "relayerSeed"}
+				ctx, coinKeeper, channelKeeper, addrs, pubKeys, privKeys, _ := createMockApp(accountSeeds)
+				sender, receiver, relayer := addrs[0], addrs[1], addrs[2]
+				_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+				require.Nil(t, err)
+				update := signedUpdate(0, usdPayout(4, 6), 0, privKeys[0])
+				authorization := types.CloseAuthorization{Fee: testCase.signedFee}
+				cryptoSig, _ := privKeys[testCase.authorizer].Sign(authorization.GetSignBytes(update))
+				authorization.Sig = types.UpdateSignature{
+					PubKey:          pubKeys[testCase.authorizer],
+					CryptoSignature: cryptoSig,
+				}
+				authorization.Fee = testCase.submittedFee
+
+				// ACTION
+				tags, sdkErr := channelKeeper.RelayCloseChannelByReceiver(ctx, update, authorization, relayer)
+
+				// CHECK RESULTS
+				if testCase.expectedCode == sdk.CodeOK {
+					require.Nil(t, sdkErr)
+					assert.Contains(t, tags, sdk.MakeTag(types.TagRelayer, relayer.String()))
+					_, found := channelKeeper.getChannel(ctx, 0)
+					assert.False(t, found)
+					assert.Equal(t, usd(994), coinKeeper.GetCoins(ctx, sender))
+				} else {
+					require.NotNil(t, sdkErr)
+					assert.Equal(t, testCase.expectedCode, sdkErr.Code())
+					_, found := channelKeeper.getChannel(ctx, 0)
+					assert.True(t, found)
+				}
+				assert.True(t, coinKeeper.GetCoins(ctx, receiver).IsEqual(testCase.expectedReceiverCoins))
+				assert.True(t, coinKeeper.GetCoins(ctx, relayer).IsEqual(testCase.expectedRelayerCoins))
+			})
+		}
+	})
+	t.Run("Tags", func(t *testing.T) {
+		// SETUP
+		accountSeeds := []string{"senderSeed", "receiverSeed"}
+		ctx, _, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+		sender, receiver := addrs[0], addrs[1]
+		channelTags := sdk.NewTags(
+			types.TagCategory, types.TxCategory,
+			types.TagChannelID, "1",
+			types.TagSender, sender.String(),
+			types.TagReceiver, receiver.String(),
+		)
+		_, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+		require.NoError(t, err)
+
+		// ACTION
+		tags, err := channelKeeper.CreateChannel(ctx, sender, receiver, usd(10), 0, nil, nil, time.Time{})
+		// CHECK RESULTS new channel id is tagged
+		require.NoError(t, err)
+		assert.Equal(t, channelTags, tags)
+
+		// ACTION
+		tags, err = channelKeeper.InitCloseChannelBySender(ctx, signedUpdate(1, usdPayout(4, 6), 0, privKeys[0]))
+		// CHECK RESULTS
+		require.NoError(t, err)
+		executionTime := ctx.BlockHeader().Time.Add(types.DefaultParams().DefaultDisputePeriod)
+		assert.Equal(t, channelTags.
+			AppendTag(types.TagPayout, "4usd;6usd").
+			AppendTag(types.TagExecutionTime, string(sdk.FormatTimeBytes(executionTime))),
+			tags)
+
+		// ACTION
+		tags = EndBlocker(ctx.WithBlockTime(executionTime), channelKeeper)
+		// CHECK RESULTS
+		assert.Equal(t, channelTags.
+			AppendTag(types.TagPayout, "4usd;6usd").
+			AppendTag(types.TagCloseType, types.CloseTypeDisputeExpired),
+			tags)
+
+		// ACTION
+		tags, err = channelKeeper.CloseChannelByReceiver(ctx, signedUpdate(0, usdPayout(3, 7), 0, privKeys[0]))
+		// CHECK RESULTS
+		require.NoError(t, err)
+		assert.Equal(t, sdk.NewTags(
+			types.TagCategory, types.TxCategory,
+			types.TagChannelID, "0",
+			types.TagSender, sender.String(),
+			types.TagReceiver, receiver.String(),
+			types.TagPayout, "3usd;7usd",
+			types.TagCloseType, types.CloseTypeReceiver,
+		), tags)
+	})
 }
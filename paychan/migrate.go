@@ -0,0 +1,192 @@
+package paychan
+
+import (
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+// Migrate converts a store written by the original version of this module to the current format.
+// It must be run once during a chain upgrade, before the EndBlocker runs with the new code.
+// Blocks remaining on pending submitted updates are converted into time using the given estimated time per block.
+// The migrations depend on each other so they are only run together, in this order.
+func Migrate(ctx sdk.Context, k Keeper, timePerBlock time.Duration) {
+	migrateToBinaryChannelKeys(ctx, k)
+	migrateToChannelIndexes(ctx, k)
+	migrateToChannelExpiry(ctx, k)
+	migrateToBlockTime(ctx, k, timePerBlock)
+	migrateToSubmittedUpdatesIndex(ctx, k)
+	migrateToEscrow(ctx, k)
+}
+
+// legacySubmittedUpdate is the store format of a SubmittedUpdate from before execution times were measured in block time.
+type legacySubmittedUpdate struct {
+	types.Update
+	ExecutionTime int64 // BlockHeight
+}
+
+// legacySubmittedUpdatesQueue is the list of channel IDs with submitted updates, stored as a single value before the queue was indexed by execution time.
+type legacySubmittedUpdatesQueue []types.ChannelID
+
+// legacySubmittedUpdatesQueueKey is the store key of the legacySubmittedUpdatesQueue.
+var legacySubmittedUpdatesQueueKey = []byte("submittedUpdatesQueue")
+
+// getLegacySubmittedUpdatesQueue loads the legacySubmittedUpdatesQueue, returning nil if it isn't in the store.
+func getLegacySubmittedUpdatesQueue(ctx sdk.Context, k Keeper) legacySubmittedUpdatesQueue {
+	var suq legacySubmittedUpdatesQueue
+	bz := ctx.KVStore(k.storeKey).Get(legacySubmittedUpdatesQueueKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &suq)
+	}
+	return suq
+}
+
+// migrateToBlockTime converts the store from block height based dispute deadlines to block time based ones.
+// It must run before migrateToSubmittedUpdatesIndex.
+// Blocks remaining on pending submitted updates are converted into time using the given estimated time per block.
+// Channels stored without a dispute period are given the current default dispute period.
+func migrateToBlockTime(ctx sdk.Context, k Keeper, timePerBlock time.Duration) {
+	defaultDisputePeriod := k.GetParams(ctx).DefaultDisputePeriod
+	channels := []types.Channel{}
+	k.iterateChannels(ctx, func(channel types.Channel) bool {
+		if channel.DisputePeriod == 0 {
+			channels = append(channels, channel)
+		}
+		return false
+	})
+	for _, channel := range channels {
+		channel.DisputePeriod = defaultDisputePeriod
+		k.setChannel(ctx, channel)
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	for _, id := range getLegacySubmittedUpdatesQueue(ctx, k) {
+		bz := store.Get(types.GetSubmittedUpdateKey(id))
+		if bz == nil {
+			panic("can't find element in queue that should exist")
+		}
+		var legacySUpdate legacySubmittedUpdate
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &legacySUpdate)
+
+		// updates already past their execution height are executed in the next EndBlocker
+		remainingBlocks := legacySUpdate.ExecutionTime - ctx.BlockHeight()
+		if remainingBlocks < 0 {
+			remainingBlocks = 0
+		}
+		k.setSubmittedUpdate(ctx, types.SubmittedUpdate{
+			Update:        legacySUpdate.Update,
+			ExecutionTime: ctx.BlockHeader().Time.Add(time.Duration(remainingBlocks) * timePerBlock),
+		})
+	}
+}
+
+// migrateToEscrow moves to holding channel coins in the module account, from the previous behaviour of removing them from supply.
+// The coins locked in channels (including penalty bonds) are added to the module account, restoring them to the total supply.
+func migrateToEscrow(ctx sdk.Context, k Keeper) {
+	locked := sdk.Coins{}
+	k.iterateChannels(ctx, func(channel types.Channel) bool {
+		locked = locked.Add(channel.Coins).Add(channel.PenaltyBond)
+		return false
+	})
+	_, err := k.bankKeeper.AddCoins(ctx, types.ModuleAddress, locked)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// migrateToSubmittedUpdatesIndex replaces the submitted updates queue, previously stored as a single list of channel IDs,
+// with store entries ordered by execution time.
+func migrateToSubmittedUpdatesIndex(ctx sdk.Context, k Keeper) {
+	store := ctx.KVStore(k.storeKey)
+	for _, id := range getLegacySubmittedUpdatesQueue(ctx, k) {
+		sUpdate, found := k.getSubmittedUpdate(ctx, id)
+		if !found {
+			panic("can't find element in queue that should exist")
+		}
+		k.addToSubmittedUpdatesQueue(ctx, sUpdate)
+	}
+	store.Delete(legacySubmittedUpdatesQueueKey)
+}
+
+// legacyLastChannelIDKey is the store key of the global id counter from before channel IDs were unsigned, when it held the last ID issued (-1 if none).
+var legacyLastChannelIDKey = []byte("lastChannelID")
+
+// migrateToBinaryChannelKeys moves channels and submitted updates from store keys containing the channel ID as a decimal string
+// to keys ending in the fixed width binary ID, and replaces the last channel id counter with a next channel id counter.
+// Stored values are copied unchanged, their encoding is the same for unsigned IDs.
+// It must run before any other migrations.
+func migrateToBinaryChannelKeys(ctx sdk.Context, k Keeper) {
+	store := ctx.KVStore(k.storeKey)
+
+	keyFormats := []struct {
+		prefix []byte
+		getKey func(types.ChannelID) []byte
+	}{
+		{types.ChannelKeyPrefix, types.GetChannelKey},
+		{types.SubmittedUpdateKeyPrefix, types.GetSubmittedUpdateKey},
+	}
+	for _, format := range keyFormats {
+		// collect entries first as the store can't be modified while iterating over it
+		var keys, values [][]byte
+		iter := sdk.KVStorePrefixIterator(store, format.prefix)
+		for ; iter.Valid(); iter.Next() {
+			keys = append(keys, iter.Key())
+			values = append(values, iter.Value())
+		}
+		iter.Close()
+
+		for i, key := range keys {
+			id, err := strconv.ParseUint(string(key[len(format.prefix):]), 10, 64)
+			if err != nil {
+				panic(err)
+			}
+			store.Delete(key)
+			store.Set(format.getKey(types.ChannelID(id)), values[i])
+		}
+	}
+
+	var lastID int64 = -1
+	if bz := store.Get(legacyLastChannelIDKey); bz != nil {
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &lastID)
+		store.Delete(legacyLastChannelIDKey)
+	}
+	k.setNextChannelID(ctx, types.ChannelID(lastID+1))
+}
+
+// migrateToChannelExpiry marks channels stored before channels could expire as never expiring.
+// Their missing expiry decodes as the unix epoch rather than the zero time, which would make them expired and let the sender reclaim them.
+// It must run after migrateToBinaryChannelKeys.
+func migrateToChannelExpiry(ctx sdk.Context, k Keeper) {
+	epoch := time.Unix(0, 0)
+	channels := []types.Channel{}
+	k.iterateChannels(ctx, func(channel types.Channel) bool {
+		if channel.Expiry.Equal(epoch) {
+			channels = append(channels, channel)
+		}
+		return false
+	})
+	store := ctx.KVStore(k.storeKey)
+	for _, channel := range channels {
+		// earlier migrations re-saving the channel will have queued it for expiry
+		store.Delete(types.GetChannelExpiryQueueKey(channel.Expiry, channel.ID))
+		channel.Expiry = time.Time{}
+		k.setChannel(ctx, channel)
+	}
+}
+
+// migrateToChannelIndexes adds every channel to the indexes of channels by sender and receiver.
+// It must run after migrateToBinaryChannelKeys.
+func migrateToChannelIndexes(ctx sdk.Context, k Keeper) {
+	channels := []types.Channel{}
+	k.iterateChannels(ctx, func(channel types.Channel) bool {
+		channels = append(channels, channel)
+		return false
+	})
+	// setChannel writes the index entries
+	for _, channel := range channels {
+		k.setChannel(ctx, channel)
+	}
+}
@@ -0,0 +1,431 @@
+package paychan
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+func TestMigrateToBlockTime(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	blockTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockHeight(100).WithBlockTime(blockTime)
+	store := ctx.KVStore(channelKeeper.storeKey)
+
+	// create two channels in the old format, one with a pending update in the future and one in the past
+	payout := types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}
+	executionHeights := []int64{150, 90}
+	queue := legacySubmittedUpdatesQueue{}
+	for i, height := range executionHeights {
+		id := types.ChannelID(i)
+		channelKeeper.setChannel(ctx, types.Channel{
+			ID:           id,
+			Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+			Coins:        sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+		})
+		legacySUpdate := legacySubmittedUpdate{
+			Update:        types.Update{ChannelID: id, Payout: payout},
+			ExecutionTime: height,
+		}
+		queue = append(queue, id)
+		store.Set(types.GetSubmittedUpdateKey(id), channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(legacySUpdate))
+	}
+	store.Set(legacySubmittedUpdatesQueueKey, channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(queue))
+
+	// ACTION
+	migrateToBlockTime(ctx, channelKeeper, 5*time.Second)
+	migrateToSubmittedUpdatesIndex(ctx, channelKeeper)
+	migrateToEscrow(ctx, channelKeeper)
+
+	// CHECK RESULTS
+	// channels given the default dispute period
+	channel, _ := channelKeeper.getChannel(ctx, 0)
+	assert.Equal(t, types.DefaultParams().DefaultDisputePeriod, channel.DisputePeriod)
+	// remaining blocks converted to time
+	su, found := channelKeeper.getSubmittedUpdate(ctx, 0)
+	assert.True(t, found)
+	assert.Equal(t, blockTime.Add(50*5*time.Second), su.ExecutionTime)
+	assert.Equal(t, payout, su.Payout)
+	// overdue updates execute in the next block
+	su, found = channelKeeper.getSubmittedUpdate(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, blockTime, su.ExecutionTime)
+	EndBlocker(ctx, channelKeeper)
+	_, found = channelKeeper.getChannel(ctx, 1)
+	assert.False(t, found)
+	_, found = channelKeeper.getChannel(ctx, 0)
+	assert.True(t, found)
+}
+
+func TestMigrateToEscrow(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, bankKeeper, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+
+	// create channels in the old format, where locked coins were removed from supply
+	channelKeeper.setChannel(ctx, types.Channel{
+		ID:           0,
+		Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+		Coins:        sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+	})
+	channelKeeper.setChannel(ctx, types.Channel{
+		ID:           1,
+		Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+		Coins:        sdk.Coins{sdk.NewInt64Coin("usd", 5)},
+		PenaltyBond:  sdk.Coins{sdk.NewInt64Coin("usd", 2)},
+	})
+	channelKeeper.setNextChannelID(ctx, 2)
+	assert.NotNil(t, LockedCoinsInvariant(channelKeeper)(ctx))
+
+	// ACTION
+	migrateToEscrow(ctx, channelKeeper)
+
+	// CHECK RESULTS
+	assert.Equal(t, sdk.Coins{sdk.NewInt64Coin("usd", 17)}, bankKeeper.GetCoins(ctx, types.ModuleAddress))
+	assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+}
+
+func TestMigrateToSubmittedUpdatesIndex(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	blockTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockTime(blockTime)
+	store := ctx.KVStore(channelKeeper.storeKey)
+
+	// create three channels with updates, queued in the old format, the last one already past its execution time
+	payout := types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}
+	executionTimes := []time.Time{blockTime.Add(time.Hour), blockTime.Add(time.Minute), blockTime.Add(-time.Minute)}
+	queue := legacySubmittedUpdatesQueue{}
+	for i, executionTime := range executionTimes {
+		id := types.ChannelID(i)
+		channelKeeper.setChannel(ctx, types.Channel{
+			ID:           id,
+			Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+			Coins:        sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+		})
+		channelKeeper.setSubmittedUpdate(ctx, types.SubmittedUpdate{
+			Update:        types.Update{ChannelID: id, Payout: payout},
+			ExecutionTime: executionTime,
+		})
+		queue = append(queue, id)
+	}
+	channelKeeper.setNextChannelID(ctx, 3)
+	store.Set(legacySubmittedUpdatesQueueKey, channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(queue))
+
+	// ACTION
+	migrateToSubmittedUpdatesIndex(ctx, channelKeeper)
+
+	// CHECK RESULTS
+	assert.False(t, store.Has(legacySubmittedUpdatesQueueKey))
+	assert.Nil(t, SubmittedUpdatesQueueInvariant(channelKeeper)(ctx))
+	// queue is in execution order
+	var queued []types.ChannelID
+	channelKeeper.iterateSubmittedUpdatesQueue(ctx, func(channelID types.ChannelID) bool {
+		queued = append(queued, channelID)
+		return false
+	})
+	assert.Equal(t, []types.ChannelID{2, 1, 0}, queued)
+}
+
+func TestMigrateToBinaryChannelKeys(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	store := ctx.KVStore(channelKeeper.storeKey)
+
+	// store channels 2 and 10 and an update for 10 under the old decimal keys, so they iterate in the wrong order
+	for _, id := range []types.ChannelID{2, 10} {
+		channel := types.Channel{
+			ID:           id,
+			Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+			Coins:        sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+		}
+		store.Set([]byte(fmt.Sprintf("channel:%d", id)), channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(channel))
+	}
+	sUpdate := types.SubmittedUpdate{
+		Update:        types.Update{ChannelID: 10, Payout: types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}},
+		ExecutionTime: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	store.Set([]byte("submittedUpdate:10"), channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(sUpdate))
+	store.Set(legacyLastChannelIDKey, channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(int64(10)))
+
+	// ACTION
+	migrateToBinaryChannelKeys(ctx, channelKeeper)
+
+	// CHECK RESULTS
+	var ids []types.ChannelID
+	channelKeeper.iterateChannels(ctx, func(channel types.Channel) bool {
+		ids = append(ids, channel.ID)
+		return false
+	})
+	assert.Equal(t, []types.ChannelID{2, 10}, ids)
+	su, found := channelKeeper.getSubmittedUpdate(ctx, 10)
+	assert.True(t, found)
+	assert.Equal(t, sUpdate, su)
+	assert.False(t, store.Has([]byte("channel:2")))
+	assert.False(t, store.Has([]byte("submittedUpdate:10")))
+	assert.False(t, store.Has(legacyLastChannelIDKey))
+	assert.Equal(t, types.ChannelID(11), channelKeeper.getNextChannelID(ctx))
+}
+
+func TestMigrateToChannelIndexes(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	store := ctx.KVStore(channelKeeper.storeKey)
+
+	// store a channel without index entries, as before the indexes existed
+	channel := types.Channel{
+		ID:           0,
+		Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+		Coins:        sdk.Coins{sdk.NewInt64Coin("usd", 10)},
+	}
+	store.Set(types.GetChannelKey(channel.ID), channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(channel))
+
+	// ACTION
+	migrateToChannelIndexes(ctx, channelKeeper)
+
+	// CHECK RESULTS
+	assert.Nil(t, ChannelIndexesInvariant(channelKeeper)(ctx))
+	var bySender, byReceiver []types.ChannelID
+	channelKeeper.iterateChannelsBySender(ctx, addrs[0], func(channel types.Channel) bool {
+		bySender = append(bySender, channel.ID)
+		return false
+	})
+	channelKeeper.iterateChannelsByReceiver(ctx, addrs[1], func(channel types.Channel) bool {
+		byReceiver = append(byReceiver, channel.ID)
+		return false
+	})
+	assert.Equal(t, []types.ChannelID{0}, bySender)
+	assert.Equal(t, []types.ChannelID{0}, byReceiver)
+}
+
+func TestMigrateToChannelExpiry(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	ctx = ctx.WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	store := ctx.KVStore(channelKeeper.storeKey)
+
+	// store channel 0 in the format from before channels had an expiry, and queue it for expiry as re-saving it would
+	type preExpiryChannel struct {
+		ID            types.ChannelID
+		Participants  []sdk.AccAddress
+		Coins         sdk.Coins
+		Withdrawn     sdk.Coins
+		DisputePeriod time.Duration
+		Multiparty    bool
+		SigThreshold  uint64
+		SigningPubKey crypto.PubKey
+		PenaltyBond   sdk.Coins
+	}
+	coins := sdk.Coins{sdk.NewInt64Coin("usd", 10)}
+	store.Set(types.GetChannelKey(0), channelKeeper.cdc.MustMarshalBinaryLengthPrefixed(preExpiryChannel{
+		ID:           0,
+		Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+		Coins:        coins,
+	}))
+	channel, _ := channelKeeper.getChannel(ctx, 0)
+	channelKeeper.setChannel(ctx, channel)
+	// store channel 1 with an expiry in the current format
+	expiry := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	channelKeeper.setChannel(ctx, types.Channel{
+		ID:           1,
+		Participants: []sdk.AccAddress{addrs[0], addrs[1]},
+		Coins:        coins,
+		Expiry:       expiry,
+	})
+	channelKeeper.setNextChannelID(ctx, 2)
+	require.True(t, channel.IsExpired(ctx.BlockHeader().Time))
+
+	// ACTION
+	migrateToChannelExpiry(ctx, channelKeeper)
+
+	// CHECK RESULTS
+	channel, _ = channelKeeper.getChannel(ctx, 0)
+	assert.True(t, channel.Expiry.IsZero())
+	channel, _ = channelKeeper.getChannel(ctx, 1)
+	assert.Equal(t, expiry, channel.Expiry)
+	assert.Nil(t, ChannelExpiryQueueInvariant(channelKeeper)(ctx))
+	EndBlocker(ctx, channelKeeper)
+	_, found := channelKeeper.getChannel(ctx, 0)
+	assert.True(t, found)
+}
+
+// baselineChannel, baselineUpdate and baselineSubmittedUpdate are the store formats from before any of the migrations,
+// when channel IDs were signed and channels had exactly two participants.
+type baselineChannel struct {
+	ID           int64
+	Participants [2]sdk.AccAddress
+	Coins        sdk.Coins
+}
+
+type baselineUpdate struct {
+	ChannelID int64
+	Payout    [2]sdk.Coins
+	Sigs      [1]types.UpdateSignature
+}
+
+type baselineSubmittedUpdate struct {
+	Update        baselineUpdate // embedded in the original, which is encoded the same as a named field
+	ExecutionTime int64          // BlockHeight
+}
+
+// writeBaselineStore fills the store as the original module would have left it after creating channels 0 to 10, closing all but 2 and 10,
+// and submitting updates to close channel 10 at block height 150 and channel 2 at block height 90.
+// Channel coins are removed from the sender's account, as they were taken out of supply rather than held in escrow.
+func writeBaselineStore(t *testing.T, ctx sdk.Context, k Keeper, bankKeeper bank.Keeper, addrs []sdk.AccAddress, privKeys []crypto.PrivKey) {
+	store := ctx.KVStore(k.storeKey)
+	coins := sdk.Coins{sdk.NewInt64Coin("usd", 10)}
+	payout := [2]sdk.Coins{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}}
+	executionHeights := map[int64]int64{10: 150, 2: 90}
+	for _, id := range []int64{10, 2} {
+		channel := baselineChannel{
+			ID:           id,
+			Participants: [2]sdk.AccAddress{addrs[0], addrs[1]},
+			Coins:        coins,
+		}
+		store.Set([]byte(fmt.Sprintf("channel:%d", id)), k.cdc.MustMarshalBinaryLengthPrefixed(channel))
+		_, sdkErr := bankKeeper.SubtractCoins(ctx, addrs[0], coins)
+		require.Nil(t, sdkErr)
+
+		// the sign bytes of the original updates are the same as for current updates without a sequence or hash locks
+		update := baselineUpdate{ChannelID: id, Payout: payout}
+		cryptoSig, err := privKeys[0].Sign(types.Update{ChannelID: types.ChannelID(id), Payout: types.Payout(payout[:])}.GetSignBytes())
+		require.NoError(t, err)
+		update.Sigs[0] = types.UpdateSignature{PubKey: privKeys[0].PubKey(), CryptoSignature: cryptoSig}
+		sUpdate := baselineSubmittedUpdate{Update: update, ExecutionTime: executionHeights[id]}
+		store.Set([]byte(fmt.Sprintf("submittedUpdate:%d", id)), k.cdc.MustMarshalBinaryLengthPrefixed(sUpdate))
+	}
+	store.Set(legacySubmittedUpdatesQueueKey, k.cdc.MustMarshalBinaryLengthPrefixed([]int64{10, 2}))
+	store.Set(legacyLastChannelIDKey, k.cdc.MustMarshalBinaryLengthPrefixed(int64(10)))
+}
+
+func TestMigrateBaselineStoreToBlockTime(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, bankKeeper, channelKeeper, addrs, _, privKeys, genAccFunding := createMockApp(accountSeeds)
+	blockTime := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockHeight(100).WithBlockTime(blockTime)
+	writeBaselineStore(t, ctx, channelKeeper, bankKeeper, addrs, privKeys)
+
+	// ACTION
+	Migrate(ctx, channelKeeper, 5*time.Second)
+
+	// CHECK RESULTS
+	assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+	// channels given the default dispute period
+	channel, found := channelKeeper.getChannel(ctx, 10)
+	require.True(t, found)
+	assert.Equal(t, types.DefaultParams().DefaultDisputePeriod, channel.DisputePeriod)
+	// remaining blocks converted to time, overdue updates execute in the next block
+	su, found := channelKeeper.getSubmittedUpdate(ctx, 10)
+	require.True(t, found)
+	assert.Equal(t, blockTime.Add(50*5*time.Second), su.ExecutionTime)
+	su, found = channelKeeper.getSubmittedUpdate(ctx, 2)
+	require.True(t, found)
+	assert.Equal(t, blockTime, su.ExecutionTime)
+	// queue is in execution order and the old queue is removed
+	var queued []types.ChannelID
+	channelKeeper.iterateSubmittedUpdatesQueue(ctx, func(channelID types.ChannelID) bool {
+		queued = append(queued, channelID)
+		return false
+	})
+	assert.Equal(t, []types.ChannelID{2, 10}, queued)
+	assert.False(t, ctx.KVStore(channelKeeper.storeKey).Has(legacySubmittedUpdatesQueueKey))
+
+	// ACTION
+	EndBlocker(ctx, channelKeeper)
+
+	// CHECK RESULTS only the overdue update is executed
+	_, found = channelKeeper.getChannel(ctx, 2)
+	assert.False(t, found)
+	_, found = channelKeeper.getChannel(ctx, 10)
+	assert.True(t, found)
+	assert.Equal(t, genAccFunding.Sub(sdk.Coins{sdk.NewInt64Coin("usd", 17)}), bankKeeper.GetCoins(ctx, addrs[0]))
+	assert.Equal(t, genAccFunding.Add(sdk.Coins{sdk.NewInt64Coin("usd", 7)}), bankKeeper.GetCoins(ctx, addrs[1]))
+	assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+}
+
+func TestMigrateBaselineStoreToBinaryChannelKeys(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, bankKeeper, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+	ctx = ctx.WithBlockHeight(100).WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	writeBaselineStore(t, ctx, channelKeeper, bankKeeper, addrs, privKeys)
+	store := ctx.KVStore(channelKeeper.storeKey)
+
+	// ACTION
+	Migrate(ctx, channelKeeper, 5*time.Second)
+
+	// CHECK RESULTS
+	assert.Nil(t, AllInvariants(channelKeeper)(ctx))
+	// channels iterate in ID order, not the string order of the old keys
+	var channels []types.Channel
+	channelKeeper.iterateChannels(ctx, func(channel types.Channel) bool {
+		channels = append(channels, channel)
+		return false
+	})
+	require.Len(t, channels, 2)
+	assert.Equal(t, types.ChannelID(2), channels[0].ID)
+	assert.Equal(t, types.ChannelID(10), channels[1].ID)
+	assert.Equal(t, []sdk.AccAddress{addrs[0], addrs[1]}, channels[1].Participants)
+	assert.Equal(t, sdk.Coins{sdk.NewInt64Coin("usd", 10)}, channels[1].Coins)
+	// submitted updates decode from the original encoding, keeping a valid signature
+	su, found := channelKeeper.getSubmittedUpdate(ctx, 10)
+	require.True(t, found)
+	assert.Nil(t, VerifyUpdate(channelKeeper.codespace, channels[1], su.Update))
+	// old keys are removed
+	assert.False(t, store.Has([]byte("channel:10")))
+	assert.False(t, store.Has([]byte("submittedUpdate:10")))
+	assert.False(t, store.Has(legacyLastChannelIDKey))
+
+	// ACTION
+	_, err := channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], sdk.Coins{sdk.NewInt64Coin("usd", 10)}, 0, nil, nil, time.Time{})
+
+	// CHECK RESULTS new channels continue from the last ID issued
+	require.NoError(t, err)
+	_, found = channelKeeper.getChannel(ctx, 11)
+	assert.True(t, found)
+}
+
+func TestMigrateBaselineStoreToChannelIndexes(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed"}
+	ctx, bankKeeper, channelKeeper, addrs, _, privKeys, _ := createMockApp(accountSeeds)
+	ctx = ctx.WithBlockHeight(100).WithBlockTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	writeBaselineStore(t, ctx, channelKeeper, bankKeeper, addrs, privKeys)
+
+	// ACTION
+	Migrate(ctx, channelKeeper, 5*time.Second)
+
+	// CHECK RESULTS
+	assert.Nil(t, ChannelIndexesInvariant(channelKeeper)(ctx))
+	var bySender, byReceiver, byOther []types.ChannelID
+	channelKeeper.iterateChannelsBySender(ctx, addrs[0], func(channel types.Channel) bool {
+		bySender = append(bySender, channel.ID)
+		return false
+	})
+	channelKeeper.iterateChannelsByReceiver(ctx, addrs[1], func(channel types.Channel) bool {
+		byReceiver = append(byReceiver, channel.ID)
+		return false
+	})
+	channelKeeper.iterateChannelsBySender(ctx, addrs[1], func(channel types.Channel) bool {
+		byOther = append(byOther, channel.ID)
+		return false
+	})
+	assert.Equal(t, []types.ChannelID{2, 10}, bySender)
+	assert.Equal(t, []types.ChannelID{2, 10}, byReceiver)
+	assert.Empty(t, byOther)
+}
@@ -0,0 +1,151 @@
+package paychan
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+// NewQuerier returns a handler for "paychan" type queries.
+// Results are returned as JSON so clients don't depend on the store encoding.
+func NewQuerier(k Keeper) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, sdk.Error) {
+		switch path[0] {
+		case types.QueryGetChannel:
+			return queryGetChannel(ctx, req, k)
+		case types.QueryGetSubmittedUpdate:
+			return queryGetSubmittedUpdate(ctx, req, k)
+		case types.QueryGetChannels:
+			return queryGetChannels(ctx, req, k)
+		case types.QueryGetParams:
+			return queryGetParams(ctx, k)
+		case types.QueryGetEscrow:
+			return queryGetEscrow(ctx, k)
+		default:
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("unknown %s query endpoint: %s", ModuleName, path[0]))
+		}
+	}
+}
+
+func queryGetChannel(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, sdk.Error) {
+	var params types.QueryChannelParams
+	err := k.cdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err.Error()))
+	}
+
+	channel, found := k.getChannel(ctx, params.ChannelID)
+	if !found {
+		return nil, types.ErrUnknownChannel(k.codespace, params.ChannelID)
+	}
+
+	bz, err := codec.MarshalJSONIndent(k.cdc, channel)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err.Error()))
+	}
+	return bz, nil
+}
+
+func queryGetSubmittedUpdate(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, sdk.Error) {
+	var params types.QueryChannelParams
+	err := k.cdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err.Error()))
+	}
+
+	sUpdate, found := k.getSubmittedUpdate(ctx, params.ChannelID)
+	if !found {
+		return nil, types.ErrUnknownSubmittedUpdate(k.codespace, params.ChannelID)
+	}
+
+	bz, err := codec.MarshalJSONIndent(k.cdc, sUpdate)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err.Error()))
+	}
+	return bz, nil
+}
+
+func queryGetChannels(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, sdk.Error) {
+	var params types.QueryChannelsParams
+	err := k.cdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(sdk.AppendMsgToErr("incorrectly formatted request data", err.Error()))
+	}
+	if params.Page < 1 || params.Limit < 1 {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("page and limit must be positive, got page %d, limit %d", params.Page, params.Limit))
+	}
+	if params.Limit > types.MaxChannelsLimit {
+		params.Limit = types.MaxChannelsLimit
+	}
+
+	// Collect the requested page of channels matching the filters, using an address index rather than scanning all channels if possible
+	iterate := k.iterateChannels
+	if !params.Sender.Empty() {
+		iterate = func(ctx sdk.Context, cb func(types.Channel) bool) {
+			k.iterateChannelsBySender(ctx, params.Sender, cb)
+		}
+	} else if !params.Receiver.Empty() {
+		iterate = func(ctx sdk.Context, cb func(types.Channel) bool) {
+			k.iterateChannelsByReceiver(ctx, params.Receiver, cb)
+		}
+	}
+	channels := types.Channels{}
+	skip := (params.Page - 1) * params.Limit
+	iterate(ctx, func(channel types.Channel) bool {
+		if !params.Sender.Empty() && !containsAddress(channel.Senders(), params.Sender) {
+			return false
+		}
+		if !params.Receiver.Empty() && !containsAddress(channel.Receivers(), params.Receiver) {
+			return false
+		}
+		if skip > 0 {
+			skip--
+			return false
+		}
+		channels = append(channels, channel)
+		return len(channels) >= params.Limit
+	})
+
+	bz, err := codec.MarshalJSONIndent(k.cdc, channels)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err.Error()))
+	}
+	return bz, nil
+}
+
+func queryGetParams(ctx sdk.Context, k Keeper) ([]byte, sdk.Error) {
+	params := k.GetParams(ctx)
+
+	bz, err := codec.MarshalJSONIndent(k.cdc, params)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err.Error()))
+	}
+	return bz, nil
+}
+
+func queryGetEscrow(ctx sdk.Context, k Keeper) ([]byte, sdk.Error) {
+	escrow := types.Escrow{
+		Address: types.ModuleAddress,
+		Coins:   k.getEscrowedCoins(ctx),
+	}
+
+	bz, err := codec.MarshalJSONIndent(k.cdc, escrow)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err.Error()))
+	}
+	return bz, nil
+}
+
+// containsAddress returns true if the address is in the list.
+func containsAddress(addrs []sdk.AccAddress, addr sdk.AccAddress) bool {
+	for _, a := range addrs {
+		if a.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
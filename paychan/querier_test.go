@@ -0,0 +1,154 @@
+package paychan
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
+)
+
+func TestQuerier(t *testing.T) {
+	// SETUP
+	accountSeeds := []string{"senderSeed", "receiverSeed", "otherSeed"}
+	ctx, _, channelKeeper, addrs, _, _, _ := createMockApp(accountSeeds)
+	querier := NewQuerier(channelKeeper)
+	coins := sdk.Coins{sdk.NewInt64Coin("usd", 10)}
+
+	// create channels 0-2 from addrs[0] to addrs[1], channel 3 from addrs[2] to addrs[0] and multiparty channel 4 between addrs[1] and addrs[2]
+	for i := 0; i < 3; i++ {
+		_, err := channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], coins, 0, nil, nil, time.Time{})
+		require.NoError(t, err)
+	}
+	_, err := channelKeeper.CreateChannel(ctx, addrs[2], addrs[0], coins, 0, nil, nil, time.Time{})
+	require.NoError(t, err)
+	_, err = channelKeeper.CreateMultipartyChannel(ctx, []sdk.AccAddress{addrs[1], addrs[2]}, types.Payout{coins, coins}, 0, 0)
+	require.NoError(t, err)
+	// flag channel 1 for closure
+	sUpdate := types.SubmittedUpdate{
+		Update: types.Update{
+			ChannelID: 1,
+			Payout:    types.Payout{sdk.Coins{sdk.NewInt64Coin("usd", 3)}, sdk.Coins{sdk.NewInt64Coin("usd", 7)}},
+		},
+		ExecutionTime: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	channelKeeper.addToSubmittedUpdatesQueue(ctx, sUpdate)
+
+	query := func(path string, params interface{}) ([]byte, sdk.Error) {
+		req := abci.RequestQuery{
+			Path: "custom/" + QuerierRoute + "/" + path,
+			Data: types.ModuleCdc.MustMarshalJSON(params),
+		}
+		return querier(ctx, []string{path}, req)
+	}
+
+	t.Run("Channel", func(t *testing.T) {
+		res, err := query(types.QueryGetChannel, types.NewQueryChannelParams(3))
+		require.NoError(t, err)
+		var channel types.Channel
+		types.ModuleCdc.MustUnmarshalJSON(res, &channel)
+		expectedChannel, _ := channelKeeper.getChannel(ctx, 3)
+		assert.Equal(t, expectedChannel, channel)
+
+		_, err = query(types.QueryGetChannel, types.NewQueryChannelParams(99))
+		require.Error(t, err)
+		assert.Equal(t, types.CodeUnknownChannel, err.Code())
+	})
+
+	t.Run("SubmittedUpdate", func(t *testing.T) {
+		res, err := query(types.QueryGetSubmittedUpdate, types.NewQueryChannelParams(1))
+		require.NoError(t, err)
+		var su types.SubmittedUpdate
+		types.ModuleCdc.MustUnmarshalJSON(res, &su)
+		assert.Equal(t, sUpdate, su)
+
+		_, err = query(types.QueryGetSubmittedUpdate, types.NewQueryChannelParams(0))
+		require.Error(t, err)
+		assert.Equal(t, types.CodeUnknownSubmittedUpdate, err.Code())
+	})
+
+	t.Run("Params", func(t *testing.T) {
+		res, err := query(types.QueryGetParams, nil)
+		require.NoError(t, err)
+		var params types.Params
+		types.ModuleCdc.MustUnmarshalJSON(res, &params)
+		assert.Equal(t, types.DefaultParams(), params)
+	})
+
+	t.Run("Escrow", func(t *testing.T) {
+		res, err := query(types.QueryGetEscrow, nil)
+		require.NoError(t, err)
+		var escrow types.Escrow
+		types.ModuleCdc.MustUnmarshalJSON(res, &escrow)
+		assert.Equal(t, types.ModuleAddress, escrow.Address)
+		assert.Equal(t, sdk.Coins{sdk.NewInt64Coin("usd", 60)}, escrow.Coins)
+	})
+
+	t.Run("Channels", func(t *testing.T) {
+		testCases := []struct {
+			name        string
+			params      types.QueryChannelsParams
+			expectedIDs []types.ChannelID
+			shouldError bool
+		}{
+			{"All", types.NewQueryChannelsParams(1, 100, nil, nil), []types.ChannelID{0, 1, 2, 3, 4}, false},
+			{"FirstPage", types.NewQueryChannelsParams(1, 3, nil, nil), []types.ChannelID{0, 1, 2}, false},
+			{"SecondPage", types.NewQueryChannelsParams(2, 3, nil, nil), []types.ChannelID{3, 4}, false},
+			{"PastLastPage", types.NewQueryChannelsParams(3, 3, nil, nil), []types.ChannelID{}, false},
+			{"BySender", types.NewQueryChannelsParams(1, 100, addrs[2], nil), []types.ChannelID{3, 4}, false},
+			{"BySenderSecondPage", types.NewQueryChannelsParams(2, 2, addrs[0], nil), []types.ChannelID{2}, false},
+			{"ByReceiver", types.NewQueryChannelsParams(1, 100, nil, addrs[1]), []types.ChannelID{0, 1, 2, 4}, false},
+			{"BySenderAndReceiver", types.NewQueryChannelsParams(1, 100, addrs[0], addrs[0]), []types.ChannelID{}, false},
+			{"BySenderAndReceiverMultiparty", types.NewQueryChannelsParams(1, 100, addrs[2], addrs[1]), []types.ChannelID{4}, false},
+			{"ZeroPage", types.NewQueryChannelsParams(0, 100, nil, nil), nil, true},
+			{"ZeroLimit", types.NewQueryChannelsParams(1, 0, nil, nil), nil, true},
+			{"NegativePage", types.NewQueryChannelsParams(-1, 100, nil, nil), nil, true},
+			{"NegativeLimit", types.NewQueryChannelsParams(1, -1, nil, nil), nil, true},
+		}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				res, err := query(types.QueryGetChannels, testCase.params)
+				if testCase.shouldError {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				var channels types.Channels
+				types.ModuleCdc.MustUnmarshalJSON(res, &channels)
+				ids := []types.ChannelID{}
+				for _, channel := range channels {
+					ids = append(ids, channel.ID)
+				}
+				assert.Equal(t, testCase.expectedIDs, ids)
+			})
+		}
+	})
+
+	t.Run("ChannelsLimitCapped", func(t *testing.T) {
+		// SETUP create enough channels to fill more than one maximum size page
+		for i := 0; i < types.MaxChannelsLimit; i++ {
+			_, err := channelKeeper.CreateChannel(ctx, addrs[0], addrs[1], sdk.Coins{sdk.NewInt64Coin("usd", 1)}, 0, nil, nil, time.Time{})
+			require.NoError(t, err)
+		}
+
+		// ACTION
+		res, err := query(types.QueryGetChannels, types.NewQueryChannelsParams(1, 1000, nil, nil))
+		// CHECK RESULTS
+		require.NoError(t, err)
+		var channels types.Channels
+		types.ModuleCdc.MustUnmarshalJSON(res, &channels)
+		assert.Len(t, channels, types.MaxChannelsLimit)
+
+		// ACTION pages are counted using the reduced limit
+		res, err = query(types.QueryGetChannels, types.NewQueryChannelsParams(2, 1000, nil, nil))
+		// CHECK RESULTS
+		require.NoError(t, err)
+		channels = types.Channels{}
+		types.ModuleCdc.MustUnmarshalJSON(res, &channels)
+		assert.Len(t, channels, 5)
+	})
+}
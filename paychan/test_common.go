@@ -8,6 +8,8 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+
+	"github.com/kava-labs/cosmos-paychan/paychan/types"
 )
 
 // Setup an example app with an in memory DB and the required keepers
@@ -18,7 +20,7 @@ func createMockApp(accountSeeds []string) (sdk.Context, bank.Keeper, Keeper, []s
 
 	// create channel keeper
 	keyChannel := sdk.NewKVStoreKey("channel")
-	channelKeeper := NewKeeper(mApp.Cdc, keyChannel, bankKeeper)
+	channelKeeper := NewKeeper(mApp.Cdc, keyChannel, bankKeeper, mApp.ParamsKeeper.Subspace(types.DefaultParamspace), types.DefaultCodespace)
 	// could add router for msg tests
 	//mapp.Router().AddRoute("channel", NewHandler(channelKeeper))
 
@@ -35,6 +37,7 @@ func createMockApp(accountSeeds []string) (sdk.Context, bank.Keeper, Keeper, []s
 	header := abci.Header{Height: mApp.LastBlockHeight() + 1}
 	mApp.BeginBlock(abci.RequestBeginBlock{Header: header})
 	ctx := mApp.BaseApp.NewContext(false, abci.Header{})
+	channelKeeper.SetParams(ctx, types.DefaultParams())
 
 	return ctx, bankKeeper, channelKeeper, addrs, pubKeys, privKeys, genAccFunding
 }
@@ -59,3 +62,39 @@ func createTestGenAccounts(accountSeeds []string, genCoins sdk.Coins) (genAccs [
 	}
 	return
 }
+
+// usd returns coins of the denomination test accounts are funded with.
+func usd(amount int64) sdk.Coins { return sdk.Coins{sdk.NewInt64Coin("usd", amount)} }
+
+// usdPayout returns a payout of the given usd amounts to each channel participant in order.
+func usdPayout(amounts ...int64) types.Payout {
+	payout := types.Payout{}
+	for _, amount := range amounts {
+		payout = append(payout, usd(amount))
+	}
+	return payout
+}
+
+// signedUpdate creates an update for a channel, signed by each of the given keys.
+func signedUpdate(channelID types.ChannelID, payout types.Payout, sequence uint64, privKeys ...crypto.PrivKey) types.Update {
+	return signUpdate(types.Update{
+		ChannelID: channelID,
+		Payout:    payout,
+		Sequence:  sequence,
+	}, privKeys...)
+}
+
+// signUpdate adds a signature from each of the given keys to an update.
+func signUpdate(update types.Update, privKeys ...crypto.PrivKey) types.Update {
+	for _, privKey := range privKeys {
+		cryptoSig, err := privKey.Sign(update.GetSignBytes())
+		if err != nil {
+			panic(err)
+		}
+		update.Sigs = append(update.Sigs, types.UpdateSignature{
+			PubKey:          privKey.PubKey(),
+			CryptoSignature: cryptoSig,
+		})
+	}
+	return update
+}
@@ -1,66 +1,208 @@
 package types
 
 import (
+	"crypto/sha256"
 	"strconv"
+	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/tendermint/tendermint/crypto"
 )
 
 // Channel represents a payment channel.
-// Participants is limited to two as currently these are unidirectional channels.
-// Last participant is designated as receiver.
+// Unidirectional channels have two participants, the first is the sender and the last is designated as receiver.
+// Multiparty channels have two or more participants who can all pay each other. Updates must be signed by SigThreshold of them.
+// A bidirectional channel is a multiparty channel with two participants.
+// Coins are the funds currently locked in the channel, Withdrawn is the total the receiver has taken out while it was open.
+// If SigningPubKey is set, the sender of a unidirectional channel signs updates with it instead of their account key.
+// PenaltyBond is locked by the sender of a unidirectional channel, and is returned to them on close unless they are penalised for closing with a stale update.
+// If Expiry is set, the receiver of a unidirectional channel must close it before then, after which the remaining coins are returned to the sender.
 type Channel struct {
-	ID           ChannelID
-	Participants [2]sdk.AccAddress // [senderAddr, receiverAddr]
-	Coins        sdk.Coins
+	ID            ChannelID
+	Participants  []sdk.AccAddress // [senderAddr, receiverAddr] in unidirectional channels
+	Coins         sdk.Coins
+	Withdrawn     sdk.Coins
+	DisputePeriod time.Duration // time a sender initiated close must wait before executing
+	Multiparty    bool          // if true all participants fund and sign updates, and any can initiate a close
+	SigThreshold  uint64        // number of participant signatures an update needs in multiparty channels
+	SigningPubKey crypto.PubKey // optional key the sender signs updates with, kept separate from the account key holding their funds
+	PenaltyBond   sdk.Coins     // optional coins the sender forfeits a share of if the receiver overrides their close with a higher payout
+	Expiry        time.Time     // optional time the channel expires, zero for channels that don't expire
+}
+
+// IsExpired returns whether the channel has an expiry that has been reached at the given time.
+func (c Channel) IsExpired(now time.Time) bool {
+	return !c.Expiry.IsZero() && !now.Before(c.Expiry)
+}
+
+// IsParticipant returns whether the address is one of the channel's participants.
+func (c Channel) IsParticipant(address sdk.AccAddress) bool {
+	for _, p := range c.Participants {
+		if p.Equals(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// Senders returns the participants that can pay into the channel.
+// That is the sender of a unidirectional channel, or all participants of a multiparty channel.
+func (c Channel) Senders() []sdk.AccAddress {
+	if c.Multiparty {
+		return c.Participants
+	}
+	return c.Participants[:1]
+}
+
+// Receivers returns the participants that can be paid by the channel.
+// That is the receiver of a unidirectional channel, or all participants of a multiparty channel.
+func (c Channel) Receivers() []sdk.AccAddress {
+	if c.Multiparty {
+		return c.Participants
+	}
+	return c.Participants[len(c.Participants)-1:]
 }
 
 // Implement fmt.Stringer interface for compatibility while sdk moves over to using yaml // TODO
 func (Channel) String() string { return "CHANNEL FORMATTING ERROR" }
 
-const ChannelDisputeTime = int64(50000) // about 3 days measured in blocks // TODO add as param in channels
+// Channels is a list of channels, as returned by queries.
+type Channels []Channel
+
+// Implement fmt.Stringer interface for compatibility while sdk moves over to using yaml // TODO
+func (Channels) String() string { return "CHANNELS FORMATTING ERROR" }
 
-type ChannelID int64 // TODO swap for uint64
+// ChannelID uniquely identifies a channel. IDs are issued in order starting from 0.
+type ChannelID uint64
 
 func NewChannelIDFromString(s string) (ChannelID, error) {
-	n, err := strconv.ParseInt(s, 10, 64) // parse using base 10, into an int64
+	n, err := strconv.ParseUint(s, 10, 64) // parse using base 10, into a uint64
 	if err != nil {
 		return 0, err
 	}
-	// TODO check ≥ 0
 	return ChannelID(n), nil
 }
 
+func (id ChannelID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
 // The data that is passed between participants as payments, and submitted to the blockchain to close a channel.
 type Update struct {
 	ChannelID ChannelID
 	Payout    Payout
-	//Sequence  int64 Not needed for unidirectional channels
-	Sigs [1]UpdateSignature // only sender needs to sign in unidirectional
+	Sigs      []UpdateSignature // only sender needs to sign in unidirectional, a threshold of participants in multiparty
+	Sequence  uint64            // orders updates in multiparty channels, not needed for unidirectional channels
+	HashLocks []HashLock        // optional conditional payments from sender to receiver, on top of the payout, only in unidirectional channels
 }
 
 func (u Update) GetSignBytes() []byte {
-	bz, err := ModuleCdc.MarshalJSON(struct {
-		ChannelID ChannelID
-		Payout    Payout
-	}{
+	bz, err := ModuleCdc.MarshalJSON(u.signDoc())
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(bz)
+}
+
+// updateSignDoc is the content of an update that is signed.
+// Sequence and HashLocks are omitted when empty so unidirectional updates are signed over the same bytes as before they existed.
+type updateSignDoc struct {
+	ChannelID ChannelID
+	Payout    Payout
+	Sequence  uint64     `json:",omitempty"`
+	HashLocks []HashLock `json:",omitempty"`
+}
+
+func (u Update) signDoc() updateSignDoc {
+	return updateSignDoc{
 		ChannelID: u.ChannelID,
-		Payout:    u.Payout})
+		Payout:    u.Payout,
+		Sequence:  u.Sequence,
+		HashLocks: u.HashLocks,
+	}
+}
 
+// CloseAuthorization lets anyone close a unidirectional channel on the receiver's behalf with a particular update,
+// for example a watchtower responding to a close by the sender while the receiver is offline.
+// It is signed by the receiver, and Fee is paid to whoever submits it out of the receiver's payout.
+type CloseAuthorization struct {
+	Fee sdk.Coins
+	Sig UpdateSignature // by the receiver's account key
+}
+
+// GetSignBytes returns the bytes the receiver signs to authorize closing with the given update.
+func (a CloseAuthorization) GetSignBytes(update Update) []byte {
+	bz, err := ModuleCdc.MarshalJSON(struct {
+		Update updateSignDoc
+		Fee    sdk.Coins
+	}{
+		Update: update.signDoc(),
+		Fee:    a.Fee,
+	})
 	if err != nil {
 		panic(err)
 	}
 	return sdk.MustSortJSON(bz)
 }
 
+// HashLockedCoins returns the total of the update's hash lock amounts.
+func (u Update) HashLockedCoins() sdk.Coins {
+	total := sdk.Coins{}
+	for _, lock := range u.HashLocks {
+		total = total.Add(lock.Amount)
+	}
+	return total
+}
+
+// HashLock makes part of a payment conditional on revealing a secret, so payments can be routed through several channels or used for atomic swaps.
+// Amount is paid to the receiver if the sha256 preimage of Hash is revealed on-chain before block height Expiry, otherwise it is refunded to the sender.
+type HashLock struct {
+	Hash   []byte // sha256 hash of the secret
+	Amount sdk.Coins
+	Expiry int64 // block height the preimage must be revealed before
+}
+
+// IsValid returns true if the hash is a sha256 hash, the amount is positive and the expiry is a valid block height.
+func (h HashLock) IsValid() bool {
+	return len(h.Hash) == sha256.Size && h.Amount.IsValid() && h.Amount.IsAllPositive() && h.Expiry > 0
+}
+
+// PendingHashLock is a hash lock from a closed channel that couldn't be settled when the channel closed.
+// It is settled once it expires, paying the receiver if the preimage was revealed in time.
+type PendingHashLock struct {
+	HashLock
+	ChannelID ChannelID
+	Index     uint64 // position of the lock in the update that closed the channel
+	Sender    sdk.AccAddress
+	Receiver  sdk.AccAddress
+}
+
+// RevealedPreimage records when the preimage of a hash was revealed on-chain.
+type RevealedPreimage struct {
+	Hash   []byte
+	Height int64 // block height the preimage was revealed at
+}
+
+// MaxPreimageLength is the maximum length in bytes of a preimage that can be revealed on-chain.
+const MaxPreimageLength = 64
+
 type UpdateSignature struct { // TODO rename to Signature
 	PubKey          crypto.PubKey
 	CryptoSignature []byte
 }
 
-// Payout is a list of coins to be paid to each of Channel.Participants
-type Payout [2]sdk.Coins
+// Payout is a list of coins to be paid to each of Channel.Participants, in the same order.
+type Payout []sdk.Coins
+
+// String returns each participant's coins separated by semicolons, eg "3usd;7usd".
+func (p Payout) String() string {
+	strs := make([]string, len(p))
+	for i, coins := range p {
+		strs[i] = coins.String()
+	}
+	return strings.Join(strs, ";")
+}
 
 func (p Payout) IsAnyNegative() bool {
 	for _, coins := range p {
@@ -89,34 +231,8 @@ func (p Payout) IsValid() bool {
 // An update that has been submitted to the blockchain, but not yet acted on.
 type SubmittedUpdate struct {
 	Update
-	ExecutionTime int64 // BlockHeight
+	ExecutionTime time.Time // block time after which the update is executed
 }
 
 // Implement fmt.Stringer interface for compatibility while sdk moves over to using yaml // TODO
 func (SubmittedUpdate) String() string { return "SUBMITTED UPDATE FORMATTING ERROR" }
-
-type SubmittedUpdatesQueue []ChannelID // not technically a queue
-
-// Check if value is in queue
-func (suq SubmittedUpdatesQueue) Contains(channelID ChannelID) bool {
-	found := false
-	for _, id := range suq {
-		if id == channelID {
-			found = true
-			break
-		}
-	}
-	return found
-}
-
-// Remove all values from queue that match argument
-func (suq *SubmittedUpdatesQueue) RemoveMatchingElements(channelID ChannelID) {
-	newSUQ := SubmittedUpdatesQueue{}
-
-	for _, id := range *suq {
-		if id != channelID {
-			newSUQ = append(newSUQ, id)
-		}
-	}
-	*suq = newSUQ
-}
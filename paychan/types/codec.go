@@ -18,5 +18,14 @@ func init() {
 // A codec needs to have implementations of interface types registered before it can serialize them.
 func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgCreate{}, "paychan/MsgCreate", nil)
+	cdc.RegisterConcrete(MsgCreateMultiparty{}, "paychan/MsgCreateMultiparty", nil)
 	cdc.RegisterConcrete(MsgSubmitUpdate{}, "paychan/MsgSubmitUpdate", nil)
+	cdc.RegisterConcrete(MsgDeposit{}, "paychan/MsgDeposit", nil)
+	cdc.RegisterConcrete(MsgWithdraw{}, "paychan/MsgWithdraw", nil)
+	cdc.RegisterConcrete(MsgCooperativeClose{}, "paychan/MsgCooperativeClose", nil)
+	cdc.RegisterConcrete(MsgRotateSigningKey{}, "paychan/MsgRotateSigningKey", nil)
+	cdc.RegisterConcrete(MsgRevealPreimage{}, "paychan/MsgRevealPreimage", nil)
+	cdc.RegisterConcrete(MsgReclaim{}, "paychan/MsgReclaim", nil)
+	cdc.RegisterConcrete(MsgRelayClose{}, "paychan/MsgRelayClose", nil)
+	cdc.RegisterConcrete(MsgBatchClose{}, "paychan/MsgBatchClose", nil)
 }
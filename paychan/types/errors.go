@@ -0,0 +1,123 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// DefaultCodespace is the codespace for all errors defined in this package.
+const DefaultCodespace sdk.CodespaceType = ModuleName
+
+// Error codes specific to the paychan module.
+// Clients can use these (along with the codespace) to tell apart why a tx or query failed.
+const (
+	CodeUnknownChannel           sdk.CodeType = 101
+	CodeUnknownSubmittedUpdate   sdk.CodeType = 102
+	CodeInvalidParticipants      sdk.CodeType = 103
+	CodeInvalidDisputePeriod     sdk.CodeType = 104
+	CodeWrongSubmitter           sdk.CodeType = 105
+	CodeNotSupportedByMultiparty sdk.CodeType = 106
+	CodeChannelClosing           sdk.CodeType = 107
+	CodeDuplicateClose           sdk.CodeType = 108
+	CodeStaleUpdate              sdk.CodeType = 109
+	CodeInvalidPayout            sdk.CodeType = 110
+	CodePayoutMismatch           sdk.CodeType = 111
+	CodeInvalidSignature         sdk.CodeType = 112
+	CodeNothingToWithdraw        sdk.CodeType = 113
+	CodeInvalidHashLock          sdk.CodeType = 114
+	CodePreimageAlreadyRevealed  sdk.CodeType = 115
+	CodeInvalidExpiry            sdk.CodeType = 116
+	CodeChannelExpired           sdk.CodeType = 117
+	CodeChannelNotExpired        sdk.CodeType = 118
+)
+
+// ErrUnknownChannel is returned when there is no open channel with the given id.
+func ErrUnknownChannel(codespace sdk.CodespaceType, channelID ChannelID) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownChannel, fmt.Sprintf("channel %d doesn't exist", channelID))
+}
+
+// ErrUnknownSubmittedUpdate is returned when a channel has no submitted update.
+func ErrUnknownSubmittedUpdate(codespace sdk.CodespaceType, channelID ChannelID) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownSubmittedUpdate, fmt.Sprintf("no submitted update found for channel %d", channelID))
+}
+
+// ErrInvalidParticipants is returned when a channel's participants, or their deposits or signature threshold, are invalid.
+func ErrInvalidParticipants(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidParticipants, msg)
+}
+
+// ErrInvalidDisputePeriod is returned when a dispute period is outside the bounds set in the params.
+func ErrInvalidDisputePeriod(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidDisputePeriod, msg)
+}
+
+// ErrWrongSubmitter is returned when a msg is sent by an account not allowed to act on the channel.
+func ErrWrongSubmitter(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeWrongSubmitter, msg)
+}
+
+// ErrNotSupportedByMultiparty is returned for actions that are only possible on unidirectional channels.
+func ErrNotSupportedByMultiparty(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotSupportedByMultiparty, msg)
+}
+
+// ErrChannelClosing is returned for actions that are not possible once a close has been submitted.
+func ErrChannelClosing(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeChannelClosing, msg)
+}
+
+// ErrDuplicateClose is returned when the sender submits a close for a channel they have already started closing.
+func ErrDuplicateClose(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeDuplicateClose, "sender can't submit an update for channel if one has already been submitted")
+}
+
+// ErrStaleUpdate is returned when an update doesn't replace the already submitted one.
+func ErrStaleUpdate(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeStaleUpdate, msg)
+}
+
+// ErrInvalidPayout is returned when an update's payout is malformed.
+func ErrInvalidPayout(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidPayout, msg)
+}
+
+// ErrPayoutMismatch is returned when an update's payout doesn't fit the coins in the channel.
+func ErrPayoutMismatch(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodePayoutMismatch, msg)
+}
+
+// ErrInvalidSignature is returned when an update isn't signed by the required keys.
+func ErrInvalidSignature(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidSignature, msg)
+}
+
+// ErrNothingToWithdraw is returned when an update doesn't pay the receiver more than they have already withdrawn.
+func ErrNothingToWithdraw(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeNothingToWithdraw, "nothing to withdraw")
+}
+
+// ErrInvalidHashLock is returned when an update's hash locks are malformed or don't fit the coins in the channel.
+func ErrInvalidHashLock(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidHashLock, msg)
+}
+
+// ErrPreimageAlreadyRevealed is returned when a preimage has already been revealed on-chain.
+func ErrPreimageAlreadyRevealed(codespace sdk.CodespaceType, hash []byte) sdk.Error {
+	return sdk.NewError(codespace, CodePreimageAlreadyRevealed, fmt.Sprintf("preimage of hash %X has already been revealed", hash))
+}
+
+// ErrInvalidExpiry is returned when a channel is created with an expiry that has already passed.
+func ErrInvalidExpiry(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidExpiry, msg)
+}
+
+// ErrChannelExpired is returned for actions that are not possible once a channel has expired.
+func ErrChannelExpired(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeChannelExpired, msg)
+}
+
+// ErrChannelNotExpired is returned when the sender tries to reclaim a channel before it has expired.
+func ErrChannelNotExpired(codespace sdk.CodespaceType, channelID ChannelID) sdk.Error {
+	return sdk.NewError(codespace, CodeChannelNotExpired, fmt.Sprintf("channel %d hasn't expired", channelID))
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// GenesisState is the state that must be provided at genesis to restore all open channels, pending closes and hash locks.
+// The submitted updates queue is not stored separately, it is rebuilt from SubmittedUpdates (in the order given) on import.
+type GenesisState struct {
+	Params            Params             `json:"params"`
+	Channels          []Channel          `json:"channels"`
+	SubmittedUpdates  []SubmittedUpdate  `json:"submitted_updates"`
+	NextChannelID     ChannelID          `json:"next_channel_id"` // id that will be given to the next channel created
+	PendingHashLocks  []PendingHashLock  `json:"pending_hash_locks"`
+	RevealedPreimages []RevealedPreimage `json:"revealed_preimages"`
+}
+
+// NewGenesisState creates a new genesis state for the paychan module.
+func NewGenesisState(params Params, channels []Channel, sUpdates []SubmittedUpdate, nextChannelID ChannelID, pendingHashLocks []PendingHashLock, revealedPreimages []RevealedPreimage) GenesisState {
+	return GenesisState{
+		Params:            params,
+		Channels:          channels,
+		SubmittedUpdates:  sUpdates,
+		NextChannelID:     nextChannelID,
+		PendingHashLocks:  pendingHashLocks,
+		RevealedPreimages: revealedPreimages,
+	}
+}
+
+// DefaultGenesisState returns a genesis state with no channels.
+func DefaultGenesisState() GenesisState {
+	return NewGenesisState(DefaultParams(), []Channel{}, []SubmittedUpdate{}, 0, []PendingHashLock{}, []RevealedPreimage{})
+}
+
+// ValidateGenesis checks a genesis state is internally consistent.
+// Signatures on submitted updates are not re-verified as they were checked when first submitted.
+func ValidateGenesis(data GenesisState) error {
+	if err := data.Params.Validate(); err != nil {
+		return err
+	}
+	channels := make(map[ChannelID]Channel, len(data.Channels))
+	for _, channel := range data.Channels {
+		if channel.ID >= data.NextChannelID {
+			return fmt.Errorf("channel id %d must be less than next channel id %d", channel.ID, data.NextChannelID)
+		}
+		if _, dup := channels[channel.ID]; dup {
+			return fmt.Errorf("duplicate channel id %d", channel.ID)
+		}
+		if len(channel.Participants) < 2 || (!channel.Multiparty && len(channel.Participants) != 2) {
+			return fmt.Errorf("channel %d has invalid number of participants %d", channel.ID, len(channel.Participants))
+		}
+		for i, addr := range channel.Participants {
+			if addr.Empty() {
+				return fmt.Errorf("channel %d has an empty participant address", channel.ID)
+			}
+			for _, other := range channel.Participants[:i] {
+				if addr.Equals(other) {
+					return fmt.Errorf("channel %d has duplicate participant %s", channel.ID, addr)
+				}
+			}
+		}
+		if channel.Multiparty && (channel.SigThreshold == 0 || channel.SigThreshold > uint64(len(channel.Participants))) {
+			return fmt.Errorf("channel %d has invalid signature threshold %d", channel.ID, channel.SigThreshold)
+		}
+		if !channel.Coins.IsValid() || !channel.Coins.IsAllPositive() {
+			return fmt.Errorf("channel %d has invalid coins: %s", channel.ID, channel.Coins)
+		}
+		if !channel.Withdrawn.IsValid() {
+			return fmt.Errorf("channel %d has invalid withdrawn coins: %s", channel.ID, channel.Withdrawn)
+		}
+		if !channel.PenaltyBond.IsValid() {
+			return fmt.Errorf("channel %d has invalid penalty bond: %s", channel.ID, channel.PenaltyBond)
+		}
+		if channel.Multiparty && !channel.Expiry.IsZero() {
+			return fmt.Errorf("multiparty channel %d can't have an expiry", channel.ID)
+		}
+		if channel.Multiparty && !channel.Withdrawn.Empty() {
+			return fmt.Errorf("multiparty channel %d can't have withdrawn coins", channel.ID)
+		}
+		if channel.DisputePeriod <= 0 {
+			return fmt.Errorf("channel %d has non positive dispute period %s", channel.ID, channel.DisputePeriod)
+		}
+		channels[channel.ID] = channel
+	}
+
+	submitted := make(map[ChannelID]bool, len(data.SubmittedUpdates))
+	for _, sUpdate := range data.SubmittedUpdates {
+		channel, found := channels[sUpdate.ChannelID]
+		if !found {
+			return fmt.Errorf("submitted update for channel %d has no matching channel", sUpdate.ChannelID)
+		}
+		if submitted[sUpdate.ChannelID] {
+			return fmt.Errorf("duplicate submitted update for channel %d", sUpdate.ChannelID)
+		}
+		if len(sUpdate.Payout) != len(channel.Participants) || !sUpdate.Payout.IsValid() || sUpdate.Payout.IsAnyNegative() {
+			return fmt.Errorf("submitted update for channel %d has invalid payout: %v", sUpdate.ChannelID, sUpdate.Payout)
+		}
+		if !channel.Coins.Add(channel.Withdrawn).IsAllGTE(sUpdate.Payout.Sum().Add(sUpdate.HashLockedCoins())) {
+			return fmt.Errorf("submitted update payout for channel %d exceeds channel coins %s", sUpdate.ChannelID, channel.Coins)
+		}
+		if !sUpdate.Payout[len(sUpdate.Payout)-1].IsAllGTE(channel.Withdrawn) {
+			return fmt.Errorf("submitted update for channel %d pays receiver less than already withdrawn %s", sUpdate.ChannelID, channel.Withdrawn)
+		}
+		for _, lock := range sUpdate.HashLocks {
+			if channel.Multiparty || !lock.IsValid() {
+				return fmt.Errorf("submitted update for channel %d has invalid hash lock", sUpdate.ChannelID)
+			}
+		}
+		submitted[sUpdate.ChannelID] = true
+	}
+
+	pending := make(map[string]bool, len(data.PendingHashLocks))
+	for _, lock := range data.PendingHashLocks {
+		if !lock.IsValid() || lock.Sender.Empty() || lock.Receiver.Empty() {
+			return fmt.Errorf("pending hash lock %d of channel %d is invalid", lock.Index, lock.ChannelID)
+		}
+		key := string(GetPendingHashLockKey(lock.Expiry, lock.ChannelID, lock.Index))
+		if pending[key] {
+			return fmt.Errorf("duplicate pending hash lock %d of channel %d", lock.Index, lock.ChannelID)
+		}
+		pending[key] = true
+	}
+
+	revealed := make(map[string]bool, len(data.RevealedPreimages))
+	for _, r := range data.RevealedPreimages {
+		if len(r.Hash) != sha256.Size || r.Height < 0 {
+			return fmt.Errorf("invalid revealed preimage record for hash %X", r.Hash)
+		}
+		if revealed[string(r.Hash)] {
+			return fmt.Errorf("duplicate revealed preimage record for hash %X", r.Hash)
+		}
+		revealed[string(r.Hash)] = true
+	}
+	return nil
+}
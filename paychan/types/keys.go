@@ -1,6 +1,12 @@
 package types
 
-import "fmt"
+import (
+	"encoding/binary"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/crypto"
+)
 
 const (
 	// ModuleName is the name of the module
@@ -13,12 +19,117 @@ const (
 	QuerierRoute = ModuleName
 )
 
+// ModuleAddress is the address of the account holding the coins locked up in channels.
+// No one has the private key for it, coins can only be moved out by the module.
+var ModuleAddress = sdk.AccAddress(crypto.AddressHash([]byte(ModuleName)))
+
+// ChannelKeyPrefix is the prefix shared by all channel store keys.
+var ChannelKeyPrefix = []byte("channel:")
+
+// SubmittedUpdateKeyPrefix is the prefix shared by all submitted update store keys.
+var SubmittedUpdateKeyPrefix = []byte("submittedUpdate:")
+
+// SubmittedUpdatesQueueKeyPrefix is the prefix shared by all submitted updates queue store keys.
+// Queue keys are ordered by the update's execution time, then channel ID, so the updates due to be executed can be iterated over without loading the rest.
+var SubmittedUpdatesQueueKeyPrefix = []byte("submittedUpdatesQueue:")
+
+// ChannelExpiryQueueKeyPrefix is the prefix shared by the store keys of the queue of channels with an expiry.
+// Queue keys are ordered by expiry time, then channel ID, so the expired channels can be iterated over without loading the rest.
+var ChannelExpiryQueueKeyPrefix = []byte("channelExpiryQueue:")
+
+// ChannelsBySenderKeyPrefix and ChannelsByReceiverKeyPrefix are the prefixes of the indexes of channels by sender and receiver address.
+// Index keys are the prefix, then the address, then the channel ID.
+var (
+	ChannelsBySenderKeyPrefix   = []byte("channelsBySender:")
+	ChannelsByReceiverKeyPrefix = []byte("channelsByReceiver:")
+)
+
+// RevealedPreimageKeyPrefix is the prefix shared by the store keys of the block height each hash lock preimage was revealed at.
+// Keys are the prefix then the sha256 hash of the preimage.
+var RevealedPreimageKeyPrefix = []byte("revealedPreimage:")
+
+// PendingHashLocksKeyPrefix is the prefix shared by the store keys of hash locks waiting to be settled.
+// Keys are ordered by the lock's expiry height, then channel ID, then the lock's position in the update, so expired locks can be iterated over without loading the rest.
+var PendingHashLocksKeyPrefix = []byte("pendingHashLocks:")
+
+// NextChannelIDKey is the store key of the counter holding the ID that will be given to the next channel created.
+var NextChannelIDKey = []byte("nextChannelID")
+
 // GetChannelKey returns the store key for the channel with the given ID.
+// Keys end in the big endian encoded ID so channels are iterated over in ID order.
 func GetChannelKey(channelID ChannelID) []byte {
-	return []byte(fmt.Sprintf("channel:%d", channelID))
+	return append(append([]byte{}, ChannelKeyPrefix...), channelIDBytes(channelID)...)
 }
 
 // GetSubmittedUpdateKey returns the store key for the SubmittedUpdate corresponding to the channel with the given ID.
 func GetSubmittedUpdateKey(channelID ChannelID) []byte {
-	return []byte(fmt.Sprintf("submittedUpdate:%d", channelID))
+	return append(append([]byte{}, SubmittedUpdateKeyPrefix...), channelIDBytes(channelID)...)
+}
+
+// GetChannelExpiryQueueKey returns the store key for a channel's entry in the channel expiry queue.
+func GetChannelExpiryQueueKey(expiry time.Time, channelID ChannelID) []byte {
+	return append(GetChannelExpiryQueueTimeKey(expiry), channelIDBytes(channelID)...)
+}
+
+// GetChannelExpiryQueueTimeKey returns the prefix shared by the expiry queue store keys of all channels expiring at the given time.
+func GetChannelExpiryQueueTimeKey(expiry time.Time) []byte {
+	return append(append([]byte{}, ChannelExpiryQueueKeyPrefix...), sdk.FormatTimeBytes(expiry)...)
+}
+
+// GetChannelsBySenderKey returns the store key for a channel's entry in the index of channels by sender.
+func GetChannelsBySenderKey(sender sdk.AccAddress, channelID ChannelID) []byte {
+	return append(GetChannelsBySenderPrefix(sender), channelIDBytes(channelID)...)
+}
+
+// GetChannelsBySenderPrefix returns the prefix shared by the index keys of all channels with the given sender.
+func GetChannelsBySenderPrefix(sender sdk.AccAddress) []byte {
+	return append(append([]byte{}, ChannelsBySenderKeyPrefix...), sender...)
+}
+
+// GetChannelsByReceiverKey returns the store key for a channel's entry in the index of channels by receiver.
+func GetChannelsByReceiverKey(receiver sdk.AccAddress, channelID ChannelID) []byte {
+	return append(GetChannelsByReceiverPrefix(receiver), channelIDBytes(channelID)...)
+}
+
+// GetChannelsByReceiverPrefix returns the prefix shared by the index keys of all channels with the given receiver.
+func GetChannelsByReceiverPrefix(receiver sdk.AccAddress) []byte {
+	return append(append([]byte{}, ChannelsByReceiverKeyPrefix...), receiver...)
+}
+
+// GetSubmittedUpdatesQueueKey returns the store key for a channel's entry in the submitted updates queue.
+func GetSubmittedUpdatesQueueKey(executionTime time.Time, channelID ChannelID) []byte {
+	return append(GetSubmittedUpdatesQueueTimeKey(executionTime), channelIDBytes(channelID)...)
+}
+
+// GetSubmittedUpdatesQueueTimeKey returns the prefix shared by the queue store keys of all updates executing at the given time.
+func GetSubmittedUpdatesQueueTimeKey(executionTime time.Time) []byte {
+	return append(append([]byte{}, SubmittedUpdatesQueueKeyPrefix...), sdk.FormatTimeBytes(executionTime)...)
+}
+
+// GetRevealedPreimageKey returns the store key for the reveal height of the preimage of the given hash.
+func GetRevealedPreimageKey(hash []byte) []byte {
+	return append(append([]byte{}, RevealedPreimageKeyPrefix...), hash...)
+}
+
+// GetPendingHashLockKey returns the store key for a pending hash lock.
+func GetPendingHashLockKey(expiry int64, channelID ChannelID, index uint64) []byte {
+	key := append(GetPendingHashLocksExpiryKey(expiry), channelIDBytes(channelID)...)
+	return append(key, uint64Bytes(index)...)
+}
+
+// GetPendingHashLocksExpiryKey returns the prefix shared by the store keys of all pending hash locks expiring at the given height.
+func GetPendingHashLocksExpiryKey(expiry int64) []byte {
+	return append(append([]byte{}, PendingHashLocksKeyPrefix...), uint64Bytes(uint64(expiry))...)
+}
+
+// channelIDBytes returns the fixed width big endian encoding of a channel ID, which sorts in the same order as the IDs.
+func channelIDBytes(channelID ChannelID) []byte {
+	return uint64Bytes(uint64(channelID))
+}
+
+// uint64Bytes returns the fixed width big endian encoding of a number, which sorts in numerical order.
+func uint64Bytes(n uint64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, n)
+	return bz
 }
@@ -2,15 +2,24 @@ package types
 
 import (
 	"fmt"
-	"strconv"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/crypto"
 )
 
 // MsgCreate is for creating a payment channel.
+// DisputePeriod is optional, if zero the module's default dispute period is used.
+// SigningPubKey is optional, if set the sender signs updates with it instead of their account key.
+// PenaltyBond is optional, a share of it is given to the receiver if they override a close by the sender with a higher payout.
+// Expiry is optional, if set the receiver must close the channel before then, after which the remaining coins are returned to the sender.
 type MsgCreate struct {
-	Participants [2]sdk.AccAddress // sender, receiver
-	Coins        sdk.Coins
+	Participants  [2]sdk.AccAddress // sender, receiver
+	Coins         sdk.Coins
+	DisputePeriod time.Duration
+	SigningPubKey crypto.PubKey
+	PenaltyBond   sdk.Coins
+	Expiry        time.Time
 }
 
 func (msg MsgCreate) Route() string { return RouterKey }
@@ -33,6 +42,14 @@ func (msg MsgCreate) ValidateBasic() sdk.Error {
 	if !(msg.Coins.IsValid() && msg.Coins.IsAllPositive()) {
 		return sdk.ErrInvalidCoins(msg.Coins.String())
 	}
+	// Check penalty bond is sorted, has valid denoms, non zero, non negative. It can be empty.
+	if !msg.PenaltyBond.IsValid() {
+		return sdk.ErrInvalidCoins(msg.PenaltyBond.String())
+	}
+	// Check dispute period isn't negative, leave checking bounds to the keeper as they are params
+	if msg.DisputePeriod < 0 {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("dispute period cannot be negative: %s", msg.DisputePeriod))
+	}
 	return nil
 }
 
@@ -41,6 +58,126 @@ func (msg MsgCreate) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Participants[0]} // select sender address
 }
 
+// MsgCreateMultiparty is for creating a payment channel where all participants deposit coins and can pay each other.
+// All participants must sign. A bidirectional channel is a multiparty channel with two participants.
+// SigThreshold is the number of participants that must sign updates, if zero all participants must sign.
+type MsgCreateMultiparty struct {
+	Participants  []sdk.AccAddress
+	Deposits      Payout // coins deposited by each participant
+	SigThreshold  uint64
+	DisputePeriod time.Duration
+}
+
+func (msg MsgCreateMultiparty) Route() string { return RouterKey }
+func (msg MsgCreateMultiparty) Type() string  { return "create_multiparty" }
+
+func (msg MsgCreateMultiparty) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgCreateMultiparty) ValidateBasic() sdk.Error {
+	// check if addresses are ok and distinct
+	if len(msg.Participants) < 2 {
+		return sdk.ErrUnknownRequest("channel must have at least two participants")
+	}
+	for i, addr := range msg.Participants {
+		if addr.Empty() {
+			return sdk.ErrInvalidAddress(addr.String())
+		}
+		for _, other := range msg.Participants[:i] {
+			if addr.Equals(other) {
+				return sdk.ErrInvalidAddress(fmt.Sprintf("duplicate participant %s", addr))
+			}
+		}
+	}
+	// Check there is a deposit for each participant
+	if len(msg.Deposits) != len(msg.Participants) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("%d deposits given for %d participants", len(msg.Deposits), len(msg.Participants)))
+	}
+	// Check if coins are sorted, have valid denoms, non negative, and some coins are deposited
+	if !msg.Deposits.IsValid() || msg.Deposits.IsAnyNegative() || msg.Deposits.Sum().Empty() {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("%v", msg.Deposits))
+	}
+	// Check threshold can be met
+	if msg.SigThreshold > uint64(len(msg.Participants)) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("signature threshold %d is more than the number of participants", msg.SigThreshold))
+	}
+	// Check dispute period isn't negative, leave checking bounds to the keeper as they are params
+	if msg.DisputePeriod < 0 {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("dispute period cannot be negative: %s", msg.DisputePeriod))
+	}
+	return nil
+}
+
+func (msg MsgCreateMultiparty) GetSigners() []sdk.AccAddress {
+	// All participants must sign as all deposit coins
+	return msg.Participants
+}
+
+// MsgDeposit is for topping up an existing payment channel.
+type MsgDeposit struct {
+	ChannelID ChannelID
+	Depositor sdk.AccAddress // must be the channel sender
+	Coins     sdk.Coins
+}
+
+func (msg MsgDeposit) Route() string { return RouterKey }
+func (msg MsgDeposit) Type() string  { return "deposit" }
+
+func (msg MsgDeposit) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgDeposit) ValidateBasic() sdk.Error {
+	// check if depositor address ok
+	if msg.Depositor.Empty() {
+		return sdk.ErrInvalidAddress(msg.Depositor.String())
+	}
+	// Check if coins are sorted, have valid denoms, non zero, non negative
+	if !(msg.Coins.IsValid() && msg.Coins.IsAllPositive()) {
+		return sdk.ErrInvalidCoins(msg.Coins.String())
+	}
+	return nil
+}
+
+func (msg MsgDeposit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Depositor}
+}
+
+// MsgRotateSigningKey is for a sender to replace the key they sign a channel's updates with.
+// It must be signed by the sender's account key.
+type MsgRotateSigningKey struct {
+	ChannelID        ChannelID
+	Sender           sdk.AccAddress
+	NewSigningPubKey crypto.PubKey
+}
+
+func (msg MsgRotateSigningKey) Route() string { return RouterKey }
+func (msg MsgRotateSigningKey) Type() string  { return "rotate_signing_key" }
+
+func (msg MsgRotateSigningKey) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgRotateSigningKey) ValidateBasic() sdk.Error {
+	// check if sender address ok
+	if msg.Sender.Empty() {
+		return sdk.ErrInvalidAddress(msg.Sender.String())
+	}
+	// check a new key is given
+	if msg.NewSigningPubKey == nil {
+		return sdk.ErrInvalidPubKey("new signing key cannot be empty")
+	}
+	return nil
+}
+
+func (msg MsgRotateSigningKey) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
 // MsgSubmitUpdate is for closing a payment channel.
 type MsgSubmitUpdate struct {
 	Update
@@ -61,10 +198,6 @@ func (msg MsgSubmitUpdate) ValidateBasic() sdk.Error {
 	if msg.Submitter.Empty() {
 		return sdk.ErrInvalidAddress(msg.Submitter.String())
 	}
-	// check id ≥ 0
-	if msg.Update.ChannelID < 0 {
-		return sdk.ErrInvalidAddress(strconv.Itoa(int(msg.ChannelID)))
-	}
 	// Check if coins are sorted, have valid denoms, non negative
 	if !msg.Update.Payout.IsValid() || msg.Update.Payout.IsAnyNegative() { // a payout can be zero
 		return sdk.ErrInvalidCoins(fmt.Sprintf("coins in payout invalid: %v", msg.Update.Payout))
@@ -75,3 +208,208 @@ func (msg MsgSubmitUpdate) ValidateBasic() sdk.Error {
 func (msg MsgSubmitUpdate) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Submitter}
 }
+
+// MsgBatchClose is for a receiver closing many channels immediately in one tx.
+// Each update is applied independently, one that fails doesn't stop the others from closing.
+type MsgBatchClose struct {
+	Updates  []Update
+	Receiver sdk.AccAddress
+}
+
+func (msg MsgBatchClose) Route() string { return RouterKey }
+func (msg MsgBatchClose) Type() string  { return "batch_close" }
+
+func (msg MsgBatchClose) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgBatchClose) ValidateBasic() sdk.Error {
+	// check if receiver address ok
+	if msg.Receiver.Empty() {
+		return sdk.ErrInvalidAddress(msg.Receiver.String())
+	}
+	if len(msg.Updates) == 0 {
+		return sdk.ErrUnknownRequest("batch must contain at least one update")
+	}
+	channelIDs := map[ChannelID]bool{}
+	for _, update := range msg.Updates {
+		// Check if coins are sorted, have valid denoms, non negative
+		if !update.Payout.IsValid() || update.Payout.IsAnyNegative() { // a payout can be zero
+			return sdk.ErrInvalidCoins(fmt.Sprintf("coins in payout invalid: %v", update.Payout))
+		}
+		// a channel can only be closed once
+		if channelIDs[update.ChannelID] {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("duplicate update for channel %d", update.ChannelID))
+		}
+		channelIDs[update.ChannelID] = true
+	}
+	return nil
+}
+
+func (msg MsgBatchClose) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Receiver}
+}
+
+// BatchCloseResult is the outcome of closing one channel in a MsgBatchClose.
+// The results of a batch are returned, json encoded, in the data of the tx result.
+type BatchCloseResult struct {
+	ChannelID ChannelID
+	Codespace sdk.CodespaceType `json:",omitempty"`
+	Code      sdk.CodeType      // sdk.CodeOK if the channel was closed
+	Log       string            `json:",omitempty"`
+}
+
+// MsgRelayClose is for closing a unidirectional channel on behalf of the receiver, with the receiver's authorization.
+// It can be submitted by anyone, who is paid the authorization's fee.
+type MsgRelayClose struct {
+	Update
+	Authorization CloseAuthorization
+	Relayer       sdk.AccAddress
+}
+
+func (msg MsgRelayClose) Route() string { return RouterKey }
+func (msg MsgRelayClose) Type() string  { return "relay_close" }
+
+func (msg MsgRelayClose) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgRelayClose) ValidateBasic() sdk.Error {
+	// check if relayer address ok
+	if msg.Relayer.Empty() {
+		return sdk.ErrInvalidAddress(msg.Relayer.String())
+	}
+	// Check if coins are sorted, have valid denoms, non negative
+	if !msg.Update.Payout.IsValid() || msg.Update.Payout.IsAnyNegative() { // a payout can be zero
+		return sdk.ErrInvalidCoins(fmt.Sprintf("coins in payout invalid: %v", msg.Update.Payout))
+	}
+	// Check fee is sorted, has valid denoms, non zero, non negative. It can be empty.
+	if !msg.Authorization.Fee.IsValid() {
+		return sdk.ErrInvalidCoins(msg.Authorization.Fee.String())
+	}
+	return nil
+}
+
+func (msg MsgRelayClose) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Relayer}
+}
+
+// MsgCooperativeClose is for closing a payment channel immediately with an update signed by all participants.
+// It can be submitted by anyone.
+type MsgCooperativeClose struct {
+	Update
+	Submitter sdk.AccAddress
+}
+
+func (msg MsgCooperativeClose) Route() string { return RouterKey }
+func (msg MsgCooperativeClose) Type() string  { return "cooperative_close" }
+
+func (msg MsgCooperativeClose) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgCooperativeClose) ValidateBasic() sdk.Error {
+	// check if submitter address ok
+	if msg.Submitter.Empty() {
+		return sdk.ErrInvalidAddress(msg.Submitter.String())
+	}
+	// Check if coins are sorted, have valid denoms, non negative
+	if !msg.Update.Payout.IsValid() || msg.Update.Payout.IsAnyNegative() { // a payout can be zero
+		return sdk.ErrInvalidCoins(fmt.Sprintf("coins in payout invalid: %v", msg.Update.Payout))
+	}
+	return nil
+}
+
+func (msg MsgCooperativeClose) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Submitter}
+}
+
+// MsgWithdraw is for a receiver to take their payout from a channel without closing it.
+type MsgWithdraw struct {
+	Update
+	Receiver sdk.AccAddress
+}
+
+func (msg MsgWithdraw) Route() string { return RouterKey }
+func (msg MsgWithdraw) Type() string  { return "withdraw" }
+
+func (msg MsgWithdraw) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgWithdraw) ValidateBasic() sdk.Error {
+	// check if receiver address ok
+	if msg.Receiver.Empty() {
+		return sdk.ErrInvalidAddress(msg.Receiver.String())
+	}
+	// Check if coins are sorted, have valid denoms, non negative
+	if !msg.Update.Payout.IsValid() || msg.Update.Payout.IsAnyNegative() { // a payout can be zero
+		return sdk.ErrInvalidCoins(fmt.Sprintf("coins in payout invalid: %v", msg.Update.Payout))
+	}
+	return nil
+}
+
+func (msg MsgWithdraw) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Receiver}
+}
+
+// MsgRevealPreimage is for revealing the preimage of a hash lock hash on-chain, unlocking payments in any channel locked with its hash.
+// It can be submitted by anyone.
+type MsgRevealPreimage struct {
+	Submitter sdk.AccAddress
+	Preimage  []byte
+}
+
+func (msg MsgRevealPreimage) Route() string { return RouterKey }
+func (msg MsgRevealPreimage) Type() string  { return "reveal_preimage" }
+
+func (msg MsgRevealPreimage) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgRevealPreimage) ValidateBasic() sdk.Error {
+	// check if submitter address ok
+	if msg.Submitter.Empty() {
+		return sdk.ErrInvalidAddress(msg.Submitter.String())
+	}
+	// check preimage isn't empty or too long to store
+	if len(msg.Preimage) == 0 || len(msg.Preimage) > MaxPreimageLength {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("preimage must be between 1 and %d bytes", MaxPreimageLength))
+	}
+	return nil
+}
+
+func (msg MsgRevealPreimage) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Submitter}
+}
+
+// MsgReclaim is for a sender to take back the coins remaining in a channel once it has expired, without needing a signed update.
+type MsgReclaim struct {
+	ChannelID ChannelID
+	Sender    sdk.AccAddress
+}
+
+func (msg MsgReclaim) Route() string { return RouterKey }
+func (msg MsgReclaim) Type() string  { return "reclaim" }
+
+func (msg MsgReclaim) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+func (msg MsgReclaim) ValidateBasic() sdk.Error {
+	// check if sender address ok
+	if msg.Sender.Empty() {
+		return sdk.ErrInvalidAddress(msg.Sender.String())
+	}
+	return nil
+}
+
+func (msg MsgReclaim) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+)
+
+// DefaultParamspace is the default name for the paychan parameter subspace
+const DefaultParamspace = ModuleName
+
+// Parameter store keys
+var (
+	KeyDefaultDisputePeriod = []byte("DefaultDisputePeriod")
+	KeyMinDisputePeriod     = []byte("MinDisputePeriod")
+	KeyMaxDisputePeriod     = []byte("MaxDisputePeriod")
+	KeyPenaltyShare         = []byte("PenaltyShare")
+)
+
+// Params are the governance controlled parameters of the paychan module.
+// Dispute periods are measured in block (BFT) time.
+type Params struct {
+	DefaultDisputePeriod time.Duration `json:"default_dispute_period"` // dispute period used when a channel doesn't specify one
+	MinDisputePeriod     time.Duration `json:"min_dispute_period"`     // shortest dispute period a channel can have
+	MaxDisputePeriod     time.Duration `json:"max_dispute_period"`     // longest dispute period a channel can have
+	PenaltyShare         sdk.Dec       `json:"penalty_share"`          // fraction of a sender's penalty bond given to the receiver when they override a stale close
+}
+
+// ParamKeyTable returns the key table for the paychan module.
+func ParamKeyTable() params.KeyTable {
+	return params.NewKeyTable().RegisterParamSet(&Params{})
+}
+
+// NewParams creates a new Params object.
+func NewParams(defaultDisputePeriod, minDisputePeriod, maxDisputePeriod time.Duration, penaltyShare sdk.Dec) Params {
+	return Params{
+		DefaultDisputePeriod: defaultDisputePeriod,
+		MinDisputePeriod:     minDisputePeriod,
+		MaxDisputePeriod:     maxDisputePeriod,
+		PenaltyShare:         penaltyShare,
+	}
+}
+
+// DefaultParams returns the default paychan module parameters.
+func DefaultParams() Params {
+	return NewParams(
+		3*24*time.Hour,
+		1*time.Hour,
+		30*24*time.Hour,
+		sdk.OneDec(),
+	)
+}
+
+// Validate checks the dispute period bounds are consistent and the penalty share is a fraction.
+func (p Params) Validate() error {
+	if err := p.ValidateDisputePeriods(); err != nil {
+		return err
+	}
+	if p.PenaltyShare.IsNil() || p.PenaltyShare.IsNegative() || p.PenaltyShare.GT(sdk.OneDec()) {
+		return fmt.Errorf("paychan parameter PenaltyShare must be between 0 and 1, is %s", p.PenaltyShare)
+	}
+	return nil
+}
+
+// ValidateDisputePeriods checks the minimum dispute period is positive and the default is between the minimum and maximum.
+// Param change proposals aren't validated, so the keeper checks this before using the dispute periods.
+func (p Params) ValidateDisputePeriods() error {
+	if p.MinDisputePeriod <= 0 {
+		return fmt.Errorf("paychan parameter MinDisputePeriod must be positive, is %s", p.MinDisputePeriod)
+	}
+	if p.MaxDisputePeriod < p.MinDisputePeriod {
+		return fmt.Errorf("paychan parameter MaxDisputePeriod (%s) must be greater than or equal to MinDisputePeriod (%s)", p.MaxDisputePeriod, p.MinDisputePeriod)
+	}
+	if p.DefaultDisputePeriod < p.MinDisputePeriod || p.DefaultDisputePeriod > p.MaxDisputePeriod {
+		return fmt.Errorf("paychan parameter DefaultDisputePeriod (%s) must be between MinDisputePeriod (%s) and MaxDisputePeriod (%s)", p.DefaultDisputePeriod, p.MinDisputePeriod, p.MaxDisputePeriod)
+	}
+	return nil
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf(`Paychan Params:
+  Default Dispute Period:  %s
+  Min Dispute Period:      %s
+  Max Dispute Period:      %s
+  Penalty Share:           %s
+`,
+		p.DefaultDisputePeriod, p.MinDisputePeriod, p.MaxDisputePeriod, p.PenaltyShare,
+	)
+}
+
+// Implements params.ParamSet
+func (p *Params) ParamSetPairs() params.ParamSetPairs {
+	return params.ParamSetPairs{
+		{Key: KeyDefaultDisputePeriod, Value: &p.DefaultDisputePeriod},
+		{Key: KeyMinDisputePeriod, Value: &p.MinDisputePeriod},
+		{Key: KeyMaxDisputePeriod, Value: &p.MaxDisputePeriod},
+		{Key: KeyPenaltyShare, Value: &p.PenaltyShare},
+	}
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// query endpoints supported by the paychan Querier
+const (
+	QueryGetChannel         = "channel"
+	QueryGetSubmittedUpdate = "submitted-update"
+	QueryGetChannels        = "channels"
+	QueryGetParams          = "params"
+	QueryGetEscrow          = "escrow"
+)
+
+// QueryChannelParams are the params for queries:
+// - 'custom/paychan/channel'
+// - 'custom/paychan/submitted-update'
+type QueryChannelParams struct {
+	ChannelID ChannelID
+}
+
+// NewQueryChannelParams creates a new instance of QueryChannelParams
+func NewQueryChannelParams(channelID ChannelID) QueryChannelParams {
+	return QueryChannelParams{
+		ChannelID: channelID,
+	}
+}
+
+// MaxChannelsLimit is the most channels returned in one page by query 'custom/paychan/channels', larger limits are reduced to it.
+const MaxChannelsLimit = 100
+
+// QueryChannelsParams are the params for query 'custom/paychan/channels'.
+// Sender and Receiver are optional filters, they are ignored if empty.
+// Page numbers start from 1.
+type QueryChannelsParams struct {
+	Page     int
+	Limit    int
+	Sender   sdk.AccAddress
+	Receiver sdk.AccAddress
+}
+
+// NewQueryChannelsParams creates a new instance of QueryChannelsParams
+func NewQueryChannelsParams(page, limit int, sender, receiver sdk.AccAddress) QueryChannelsParams {
+	return QueryChannelsParams{
+		Page:     page,
+		Limit:    limit,
+		Sender:   sender,
+		Receiver: receiver,
+	}
+}
+
+// Escrow is the result of query 'custom/paychan/escrow', the account holding all coins locked in channels and its balance.
+type Escrow struct {
+	Address sdk.AccAddress
+	Coins   sdk.Coins
+}
+
+// Implement fmt.Stringer interface for compatibility while sdk moves over to using yaml // TODO
+func (Escrow) String() string { return "ESCROW FORMATTING ERROR" }
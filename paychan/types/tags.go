@@ -0,0 +1,30 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Tag keys and values
+var (
+	TxCategory = "paychan"
+
+	TagCategory      = sdk.TagCategory
+	TagChannelID     = "channel-id"
+	TagSender        = "sender"
+	TagReceiver      = "receiver"
+	TagParticipant   = "participant" // one for each participant of a multiparty channel
+	TagExecutionTime = "execution-time"
+	TagPayout        = "payout"
+	TagCloseType     = "close-type"
+	TagHash          = "hash" // hex encoded hash of a hash lock preimage
+	TagHashLock      = "hash-lock"
+	TagRelayer       = "relayer" // submitter of a receiver close made with the receiver's close authorization
+
+	CloseTypeReceiver       = "receiver"        // closed immediately by the receiver
+	CloseTypeCooperative    = "cooperative"     // closed immediately with an update signed by all participants
+	CloseTypeDisputeExpired = "dispute-expired" // closed by the EndBlocker once the dispute period of a submitted update ended
+	CloseTypeExpired        = "expired"         // reclaimed by the sender, or closed by the EndBlocker, once the channel's expiry was reached
+
+	HashLockClaimed  = "claimed"  // pending hash lock paid to the receiver as its preimage was revealed in time
+	HashLockRefunded = "refunded" // pending hash lock returned to the sender as it expired
+)
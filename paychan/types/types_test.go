@@ -1,33 +1,53 @@
 package types
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/mock"
 	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
 )
 
-func TestSubmittedUpdatesQueue(t *testing.T) {
-	t.Run("RemoveMatchingElements", func(t *testing.T) {
-		// SETUP
-		q := SubmittedUpdatesQueue{4, 8, 23, 0, 5645657}
-		// ACTION
-		q.RemoveMatchingElements(23)
-		// CHECK RESULTS
-		expectedQ := SubmittedUpdatesQueue{4, 8, 0, 5645657}
-		assert.Equal(t, expectedQ, q)
-
-		// SETUP
-		q = SubmittedUpdatesQueue{0}
-		// ACTION
-		q.RemoveMatchingElements(0)
-		// CHECK RESULTS
-		expectedQ = SubmittedUpdatesQueue{}
-		assert.Equal(t, expectedQ, q)
+func TestChannelID(t *testing.T) {
+	t.Run("NewChannelIDFromString", func(t *testing.T) {
+		id, err := NewChannelIDFromString("18446744073709551615")
+		assert.NoError(t, err)
+		assert.Equal(t, ChannelID(18446744073709551615), id)
+
+		_, err = NewChannelIDFromString("-1")
+		assert.Error(t, err)
+	})
+	t.Run("KeyOrder", func(t *testing.T) {
+		// keys sort in id order
+		assert.True(t, bytes.Compare(GetChannelKey(2), GetChannelKey(10)) < 0)
+		assert.True(t, bytes.Compare(GetChannelKey(255), GetChannelKey(256)) < 0)
+		assert.True(t, bytes.Compare(GetSubmittedUpdateKey(9), GetSubmittedUpdateKey(10)) < 0)
 	})
 }
 
+func TestSubmittedUpdatesQueueKey(t *testing.T) {
+	t1 := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Nanosecond)
+	// keys in expected order
+	keys := [][]byte{
+		GetSubmittedUpdatesQueueKey(t1, 2),
+		GetSubmittedUpdatesQueueKey(t1, 256),
+		GetSubmittedUpdatesQueueKey(t2, 0),
+		GetSubmittedUpdatesQueueKey(t2, 1),
+		GetSubmittedUpdatesQueueKey(t1.AddDate(1, 0, 0), 0),
+	}
+	for i := 1; i < len(keys); i++ {
+		assert.True(t, bytes.Compare(keys[i-1], keys[i]) < 0, "key %d not ordered before key %d", i-1, i)
+	}
+	// keys at a given time are covered by that time's prefix
+	assert.True(t, bytes.HasPrefix(keys[1], GetSubmittedUpdatesQueueTimeKey(t1)))
+	assert.False(t, bytes.HasPrefix(keys[2], GetSubmittedUpdatesQueueTimeKey(t1)))
+}
+
 func TestPayout(t *testing.T) {
 
 	t.Run("Sum", func(t *testing.T) {
@@ -51,23 +71,209 @@ func TestPayout(t *testing.T) {
 }
 
 func TestMsgCreate(t *testing.T) {
+	tests := []struct {
+		name          string
+		sender        sdk.AccAddress
+		receiver      sdk.AccAddress
+		coins         sdk.Coins
+		disputePeriod time.Duration
+		expectPass    bool
+	}{
+		{"happyPath", testAddrs[0], testAddrs[1], cs(c("gbp", 1000)), 0, true},
+		{"customDisputePeriod", testAddrs[0], testAddrs[1], cs(c("gbp", 1000)), time.Hour, true},
+		{"negativeDisputePeriod", testAddrs[0], testAddrs[1], cs(c("gbp", 1000)), -1, false},
+		{"emptyAddresses", sdk.AccAddress{}, sdk.AccAddress{}, cs(c("gbp", 1000)), 0, false},
+		{"emptyCoins", testAddrs[0], testAddrs[1], cs(), 0, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgCreate{
+				Participants:  [2]sdk.AccAddress{tc.sender, tc.receiver},
+				Coins:         tc.coins,
+				DisputePeriod: tc.disputePeriod,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+func TestMsgCreateMultiparty(t *testing.T) {
+	addrs := func(as ...sdk.AccAddress) []sdk.AccAddress { return as }
+	tests := []struct {
+		name          string
+		participants  []sdk.AccAddress
+		deposits      Payout
+		sigThreshold  uint64
+		disputePeriod time.Duration
+		expectPass    bool
+	}{
+		{"happyPath", addrs(testAddrs[0], testAddrs[1]), Payout{cs(c("gbp", 1000)), cs(c("gbp", 500))}, 0, 0, true},
+		{"oneSidedDeposit", addrs(testAddrs[0], testAddrs[1]), Payout{cs(c("gbp", 1000)), cs()}, 0, 0, true},
+		{"threeParticipants", addrs(testAddrs[0], testAddrs[1], testAddrs[2]), Payout{cs(c("gbp", 1000)), cs(), cs()}, 2, 0, true},
+		{"onlyOneParticipant", addrs(testAddrs[0]), Payout{cs(c("gbp", 1000))}, 0, 0, false},
+		{"missingDeposit", addrs(testAddrs[0], testAddrs[1], testAddrs[2]), Payout{cs(c("gbp", 1000)), cs()}, 0, 0, false},
+		{"thresholdTooHigh", addrs(testAddrs[0], testAddrs[1]), Payout{cs(c("gbp", 1000)), cs()}, 3, 0, false},
+		{"negativeDisputePeriod", addrs(testAddrs[0], testAddrs[1]), Payout{cs(c("gbp", 1000)), cs()}, 0, -1, false},
+		{"emptyAddress", addrs(testAddrs[0], sdk.AccAddress{}), Payout{cs(c("gbp", 1000)), cs()}, 0, 0, false},
+		{"sameAddresses", addrs(testAddrs[0], testAddrs[0]), Payout{cs(c("gbp", 1000)), cs()}, 0, 0, false},
+		{"emptyDeposits", addrs(testAddrs[0], testAddrs[1]), Payout{cs(), cs()}, 0, 0, false},
+		{"negativeDeposit", addrs(testAddrs[0], testAddrs[1]), Payout{cs(c("gbp", 1000)), sdk.Coins{sdk.Coin{Denom: "gbp", Amount: sdk.NewInt(-1)}}}, 0, 0, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgCreateMultiparty{
+				Participants:  tc.participants,
+				Deposits:      tc.deposits,
+				SigThreshold:  tc.sigThreshold,
+				DisputePeriod: tc.disputePeriod,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+func TestMsgDeposit(t *testing.T) {
+	tests := []struct {
+		name       string
+		channelID  ChannelID
+		depositor  sdk.AccAddress
+		coins      sdk.Coins
+		expectPass bool
+	}{
+		{"happyPath", 0, testAddrs[0], cs(c("gbp", 1000)), true},
+		{"emptyAddress", 0, sdk.AccAddress{}, cs(c("gbp", 1000)), false},
+		{"emptyCoins", 0, testAddrs[0], cs(), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgDeposit{
+				ChannelID: tc.channelID,
+				Depositor: tc.depositor,
+				Coins:     tc.coins,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+
+func TestMsgRotateSigningKey(t *testing.T) {
 	tests := []struct {
 		name       string
+		channelID  ChannelID
 		sender     sdk.AccAddress
+		pubKey     crypto.PubKey
+		expectPass bool
+	}{
+		{"happyPath", 0, testAddrs[0], ed25519.GenPrivKey().PubKey(), true},
+		{"emptyAddr", 0, sdk.AccAddress{}, ed25519.GenPrivKey().PubKey(), false},
+		{"noKey", 0, testAddrs[0], nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgRotateSigningKey{
+				ChannelID:        tc.channelID,
+				Sender:           tc.sender,
+				NewSigningPubKey: tc.pubKey,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+
+func TestMsgRevealPreimage(t *testing.T) {
+	tests := []struct {
+		name       string
+		submitter  sdk.AccAddress
+		preimage   []byte
+		expectPass bool
+	}{
+		{"happyPath", testAddrs[0], []byte("secret"), true},
+		{"emptyAddr", sdk.AccAddress{}, []byte("secret"), false},
+		{"emptyPreimage", testAddrs[0], nil, false},
+		{"longPreimage", testAddrs[0], make([]byte, MaxPreimageLength+1), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgRevealPreimage{
+				Submitter: tc.submitter,
+				Preimage:  tc.preimage,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+
+func TestMsgReclaim(t *testing.T) {
+	tests := []struct {
+		name       string
+		channelID  ChannelID
+		sender     sdk.AccAddress
+		expectPass bool
+	}{
+		{"happyPath", 0, testAddrs[0], true},
+		{"emptyAddr", 0, sdk.AccAddress{}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgReclaim{
+				ChannelID: tc.channelID,
+				Sender:    tc.sender,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+
+func TestMsgBatchClose(t *testing.T) {
+	update := func(id ChannelID) Update {
+		return Update{id, Payout{cs(c("usd", 4)), cs(c("usd", 6))}, []UpdateSignature{{}}, 0, nil}
+	}
+	tests := []struct {
+		name       string
 		receiver   sdk.AccAddress
-		coins      sdk.Coins
+		updates    []Update
 		expectPass bool
 	}{
-		{"happyPath", testAddrs[0], testAddrs[1], cs(c("gbp", 1000)), true},
-		{"emptyAddresses", sdk.AccAddress{}, sdk.AccAddress{}, cs(c("gbp", 1000)), false},
-		{"emptyCoins", testAddrs[0], testAddrs[1], cs(), false},
+		{"happyPath", testAddrs[0], []Update{update(0), update(1)}, true},
+		{"emptyAddr", sdk.AccAddress{}, []Update{update(0), update(1)}, false},
+		{"noUpdates", testAddrs[0], nil, false},
+		{"duplicateChannel", testAddrs[0], []Update{update(0), update(1), update(0)}, false},
+		{"invalidPayout", testAddrs[0], []Update{update(0), {1, Payout{sdk.Coins{c("usd", 1), c("eur", 1)}, cs(c("usd", 6))}, nil, 0, nil}}, false},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			msg := MsgCreate{
-				Participants: [2]sdk.AccAddress{tc.sender, tc.receiver},
-				Coins:        tc.coins,
+			msg := MsgBatchClose{
+				Updates:  tc.updates,
+				Receiver: tc.receiver,
 			}
 			if tc.expectPass {
 				assert.NoError(t, msg.ValidateBasic())
@@ -77,6 +283,37 @@ func TestMsgCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestMsgRelayClose(t *testing.T) {
+	update := Update{0, Payout{cs(c("usd", 4)), cs(c("usd", 6))}, []UpdateSignature{{}}, 0, nil}
+	tests := []struct {
+		name       string
+		relayer    sdk.AccAddress
+		fee        sdk.Coins
+		expectPass bool
+	}{
+		{"happyPath", testAddrs[0], cs(c("usd", 1)), true},
+		{"noFee", testAddrs[0], sdk.Coins{}, true},
+		{"emptyAddr", sdk.AccAddress{}, cs(c("usd", 1)), false},
+		{"unsortedFee", testAddrs[0], sdk.Coins{c("usd", 1), c("eur", 1)}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgRelayClose{
+				Update:        update,
+				Authorization: CloseAuthorization{Fee: tc.fee},
+				Relayer:       tc.relayer,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+
 func TestMsgSubmitUpdate(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -84,9 +321,8 @@ func TestMsgSubmitUpdate(t *testing.T) {
 		update     Update
 		expectPass bool
 	}{
-		{"happyPath", testAddrs[0], Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, [1]UpdateSignature{{}}}, true},
-		{"negativeID", testAddrs[0], Update{-9999999, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, [1]UpdateSignature{{}}}, false},
-		{"emptyAddr", sdk.AccAddress{}, Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, [1]UpdateSignature{{}}}, false},
+		{"happyPath", testAddrs[0], Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, []UpdateSignature{{}}, 0, nil}, true},
+		{"emptyAddr", sdk.AccAddress{}, Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, []UpdateSignature{{}}, 0, nil}, false},
 	}
 
 	for _, tc := range tests {
@@ -104,9 +340,179 @@ func TestMsgSubmitUpdate(t *testing.T) {
 	}
 }
 
+func TestMsgCooperativeClose(t *testing.T) {
+	tests := []struct {
+		name       string
+		submitter  sdk.AccAddress
+		update     Update
+		expectPass bool
+	}{
+		{"happyPath", testAddrs[0], Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, []UpdateSignature{{}, {}}, 0, nil}, true},
+		{"emptyAddr", sdk.AccAddress{}, Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, []UpdateSignature{{}, {}}, 0, nil}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgCooperativeClose{
+				Submitter: tc.submitter,
+				Update:    tc.update,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+
+func TestMsgWithdraw(t *testing.T) {
+	tests := []struct {
+		name       string
+		receiver   sdk.AccAddress
+		update     Update
+		expectPass bool
+	}{
+		{"happyPath", testAddrs[0], Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, []UpdateSignature{{}}, 0, nil}, true},
+		{"emptyAddr", sdk.AccAddress{}, Update{0, Payout{cs(c("usd", 1000)), cs(c("gbp", 1000))}, []UpdateSignature{{}}, 0, nil}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := MsgWithdraw{
+				Receiver: tc.receiver,
+				Update:   tc.update,
+			}
+			if tc.expectPass {
+				assert.NoError(t, msg.ValidateBasic())
+			} else {
+				assert.Error(t, msg.ValidateBasic())
+			}
+		})
+	}
+}
+
 var _, testAddrs = mock.GeneratePrivKeyAddressPairs(10)
 
 // TODO change these to create the raw types without validation
 func i(in int64) sdk.Int                    { return sdk.NewInt(in) }
 func c(denom string, amount int64) sdk.Coin { return sdk.NewInt64Coin(denom, amount) }
 func cs(coins ...sdk.Coin) sdk.Coins        { return sdk.NewCoins(coins...) }
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     Params
+		expectPass bool
+	}{
+		{"default", DefaultParams(), true},
+		{"allEqual", NewParams(time.Hour, time.Hour, time.Hour, sdk.OneDec()), true},
+		{"zeroMin", NewParams(time.Hour, 0, time.Hour, sdk.OneDec()), false},
+		{"maxBelowMin", NewParams(2*time.Hour, 2*time.Hour, time.Hour, sdk.OneDec()), false},
+		{"defaultBelowMin", NewParams(time.Hour, 2*time.Hour, 3*time.Hour, sdk.OneDec()), false},
+		{"defaultAboveMax", NewParams(4*time.Hour, 2*time.Hour, 3*time.Hour, sdk.OneDec()), false},
+		{"zeroPenaltyShare", NewParams(time.Hour, time.Hour, time.Hour, sdk.ZeroDec()), true},
+		{"negativePenaltyShare", NewParams(time.Hour, time.Hour, time.Hour, sdk.NewDec(-1)), false},
+		{"penaltyShareAboveOne", NewParams(time.Hour, time.Hour, time.Hour, sdk.NewDec(2)), false},
+		{"nilPenaltyShare", NewParams(time.Hour, time.Hour, time.Hour, sdk.Dec{}), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectPass {
+				assert.NoError(t, tc.params.Validate())
+			} else {
+				assert.Error(t, tc.params.Validate())
+			}
+		})
+	}
+}
+
+func TestValidateGenesis(t *testing.T) {
+	channel := Channel{
+		ID:            0,
+		Participants:  []sdk.AccAddress{testAddrs[0], testAddrs[1]},
+		Coins:         cs(c("usd", 10)),
+		DisputePeriod: time.Hour,
+	}
+	sUpdate := SubmittedUpdate{
+		Update: Update{
+			ChannelID: 0,
+			Payout:    Payout{cs(c("usd", 3)), cs(c("usd", 7))},
+		},
+		ExecutionTime: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	badPayoutSUpdate := sUpdate
+	badPayoutSUpdate.Payout = Payout{cs(c("usd", 3)), cs(c("usd", 8))}
+	emptyCoinsChannel := channel
+	emptyCoinsChannel.Coins = cs()
+	noDisputePeriodChannel := channel
+	noDisputePeriodChannel.DisputePeriod = 0
+	multipartyChannel := channel
+	multipartyChannel.Participants = []sdk.AccAddress{testAddrs[0], testAddrs[1], testAddrs[2]}
+	multipartyChannel.Multiparty = true
+	multipartyChannel.SigThreshold = 2
+	noThresholdChannel := multipartyChannel
+	noThresholdChannel.SigThreshold = 0
+	threePartyUnidirectionalChannel := multipartyChannel
+	threePartyUnidirectionalChannel.Multiparty = false
+	expiringChannel := channel
+	expiringChannel.Expiry = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	expiringMultipartyChannel := multipartyChannel
+	expiringMultipartyChannel.Expiry = expiringChannel.Expiry
+	hash := make([]byte, 32)
+	hashLockedSUpdate := sUpdate
+	hashLockedSUpdate.Payout = Payout{cs(c("usd", 3)), cs(c("usd", 6))}
+	hashLockedSUpdate.HashLocks = []HashLock{{Hash: hash, Amount: cs(c("usd", 1)), Expiry: 100}}
+	overLockedSUpdate := hashLockedSUpdate
+	overLockedSUpdate.HashLocks = []HashLock{{Hash: hash, Amount: cs(c("usd", 2)), Expiry: 100}}
+	pendingLock := PendingHashLock{
+		HashLock:  HashLock{Hash: hash, Amount: cs(c("usd", 1)), Expiry: 100},
+		ChannelID: 0,
+		Sender:    testAddrs[0],
+		Receiver:  testAddrs[1],
+	}
+	noExpiryLock := pendingLock
+	noExpiryLock.Expiry = 0
+	revealed := RevealedPreimage{Hash: hash, Height: 10}
+
+	tests := []struct {
+		name       string
+		genState   GenesisState
+		expectPass bool
+	}{
+		{"default", DefaultGenesisState(), true},
+		{"happyPath", NewGenesisState(DefaultParams(), []Channel{channel}, []SubmittedUpdate{sUpdate}, 1, nil, nil), true},
+		{"channelIDTooHigh", NewGenesisState(DefaultParams(), []Channel{channel}, nil, 0, nil, nil), false},
+		{"duplicateChannel", NewGenesisState(DefaultParams(), []Channel{channel, channel}, nil, 1, nil, nil), false},
+		{"emptyCoins", NewGenesisState(DefaultParams(), []Channel{emptyCoinsChannel}, nil, 1, nil, nil), false},
+		{"noDisputePeriod", NewGenesisState(DefaultParams(), []Channel{noDisputePeriodChannel}, nil, 1, nil, nil), false},
+		{"updateWithoutChannel", NewGenesisState(DefaultParams(), nil, []SubmittedUpdate{sUpdate}, 1, nil, nil), false},
+		{"duplicateUpdate", NewGenesisState(DefaultParams(), []Channel{channel}, []SubmittedUpdate{sUpdate, sUpdate}, 1, nil, nil), false},
+		{"invalidParams", NewGenesisState(NewParams(time.Hour, 2*time.Hour, 3*time.Hour, sdk.OneDec()), nil, nil, 0, nil, nil), false},
+		{"payoutMismatch", NewGenesisState(DefaultParams(), []Channel{channel}, []SubmittedUpdate{badPayoutSUpdate}, 1, nil, nil), false},
+		{"multiparty", NewGenesisState(DefaultParams(), []Channel{multipartyChannel}, nil, 1, nil, nil), true},
+		{"multipartyNoThreshold", NewGenesisState(DefaultParams(), []Channel{noThresholdChannel}, nil, 1, nil, nil), false},
+		{"threePartyUnidirectional", NewGenesisState(DefaultParams(), []Channel{threePartyUnidirectionalChannel}, nil, 1, nil, nil), false},
+		{"payoutWrongLength", NewGenesisState(DefaultParams(), []Channel{multipartyChannel}, []SubmittedUpdate{sUpdate}, 1, nil, nil), false},
+		{"expiringChannel", NewGenesisState(DefaultParams(), []Channel{expiringChannel}, nil, 1, nil, nil), true},
+		{"expiringMultiparty", NewGenesisState(DefaultParams(), []Channel{expiringMultipartyChannel}, nil, 1, nil, nil), false},
+		{"hashLockedUpdate", NewGenesisState(DefaultParams(), []Channel{channel}, []SubmittedUpdate{hashLockedSUpdate}, 1, nil, nil), true},
+		{"hashLocksExceedChannel", NewGenesisState(DefaultParams(), []Channel{channel}, []SubmittedUpdate{overLockedSUpdate}, 1, nil, nil), false},
+		{"hashLockState", NewGenesisState(DefaultParams(), nil, nil, 1, []PendingHashLock{pendingLock}, []RevealedPreimage{revealed}), true},
+		{"invalidPendingHashLock", NewGenesisState(DefaultParams(), nil, nil, 1, []PendingHashLock{noExpiryLock}, nil), false},
+		{"duplicatePendingHashLock", NewGenesisState(DefaultParams(), nil, nil, 1, []PendingHashLock{pendingLock, pendingLock}, nil), false},
+		{"duplicateRevealedPreimage", NewGenesisState(DefaultParams(), nil, nil, 1, nil, []RevealedPreimage{revealed, revealed}), false},
+		{"invalidRevealedPreimageHash", NewGenesisState(DefaultParams(), nil, nil, 1, nil, []RevealedPreimage{{Hash: hash[:31], Height: 10}}), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectPass {
+				assert.NoError(t, ValidateGenesis(tc.genState))
+			} else {
+				assert.Error(t, ValidateGenesis(tc.genState))
+			}
+		})
+	}
+}